@@ -0,0 +1,75 @@
+// Command sandarb is the Sandarb command-line tool.
+//
+//	sandarb sync plan  [-dir governance]
+//	sandarb sync apply [-dir governance] [-m message] [-auto-approve] [-created-by name]
+//
+// The server and credentials come from SANDARB_URL and SANDARB_API_KEY. "sync plan" exits
+// with status 2 when there are changes, so CI can detect drift between git and the server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb"
+	"github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb/gitsync"
+)
+
+const usage = `usage:
+  sandarb sync plan  [-dir governance]
+  sandarb sync apply [-dir governance] [-m message] [-auto-approve] [-created-by name]
+`
+
+func main() {
+	if len(os.Args) < 3 || os.Args[1] != "sync" || (os.Args[2] != "plan" && os.Args[2] != "apply") {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(64)
+	}
+	os.Exit(runSync(os.Args[2], os.Args[3:]))
+}
+
+func runSync(verb string, args []string) int {
+	fs := flag.NewFlagSet("sandarb sync "+verb, flag.ExitOnError)
+	dir := fs.String("dir", "governance", "definitions directory (with contexts/ and prompts/)")
+	var ao gitsync.ApplyOptions
+	if verb == "apply" {
+		fs.StringVar(&ao.CommitMessage, "m", "sandarb sync", "commit message for new revisions and versions")
+		fs.BoolVar(&ao.AutoApprove, "auto-approve", false, "activate content changes without approval")
+		fs.StringVar(&ao.CreatedBy, "created-by", "", "author recorded on new revisions")
+	}
+	fs.Parse(args)
+
+	defs, err := gitsync.Load(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	client := sandarb.NewClient()
+	defer client.Close(context.Background())
+	plan, err := defs.Plan(client)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "plan:", err)
+		return 1
+	}
+	plan.WriteTo(os.Stdout)
+	if verb == "plan" {
+		if plan.HasChanges() {
+			return 2
+		}
+		return 0
+	}
+	if !plan.HasChanges() {
+		return 0
+	}
+	res, err := plan.Apply(client, ao)
+	for _, ch := range res.Applied {
+		fmt.Printf("applied: %s %s (%s)\n", ch.Kind, ch.Name, ch.Action)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "apply:", err)
+		return 1
+	}
+	return 0
+}
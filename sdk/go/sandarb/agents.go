@@ -0,0 +1,120 @@
+package sandarb
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Agent statuses set by SuspendAgent and RevokeAgent; new agents are AgentActive.
+const (
+	AgentActive    = "active"
+	AgentSuspended = "suspended"
+	AgentRevoked   = "revoked"
+)
+
+// Agent is a registered agent (agents table).
+type Agent struct {
+	// ID is the registry's internal ID, used by GetAgent, SuspendAgent and RevokeAgent.
+	ID string `json:"id"`
+	// AgentID is the identifier the agent presents in API calls.
+	AgentID           string                 `json:"agentId"`
+	OrgID             string                 `json:"orgId"`
+	Name              string                 `json:"name"`
+	Description       *string                `json:"description,omitempty"`
+	A2AURL            string                 `json:"a2aUrl"`
+	AgentCard         map[string]interface{} `json:"agentCard,omitempty"`
+	Status            string                 `json:"status"`
+	ApprovalStatus    string                 `json:"approvalStatus"`
+	ApprovedBy        *string                `json:"approvedBy,omitempty"`
+	ApprovedAt        *string                `json:"approvedAt,omitempty"`
+	OwnerTeam         *string                `json:"ownerTeam,omitempty"`
+	ToolsUsed         []string               `json:"toolsUsed,omitempty"`
+	AllowedDataScopes []string               `json:"allowedDataScopes,omitempty"`
+	PIIHandling       bool                   `json:"piiHandling"`
+	RegulatoryScope   []string               `json:"regulatoryScope,omitempty"`
+	CreatedAt         string                 `json:"createdAt"`
+	UpdatedAt         string                 `json:"updatedAt"`
+	UpdatedBy         *string                `json:"updatedBy,omitempty"`
+	// LastAccessedAt is when the agent last called Sandarb (GetAgent only).
+	LastAccessedAt *string `json:"lastAccessedAt,omitempty"`
+}
+
+// AgentFilter narrows ListAgents. Zero fields are not filtered on; Limit defaults to 50 (max 500).
+type AgentFilter struct {
+	OrgID          string
+	ApprovalStatus string
+	Limit          int
+	Offset         int
+}
+
+// AgentList is one page of ListAgents results.
+type AgentList struct {
+	Agents []Agent `json:"agents"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// ListAgents returns a page of registered agents matching filter.
+func (c *Client) ListAgents(filter AgentFilter, opts ...RequestOption) (*AgentList, error) {
+	q := url.Values{}
+	if filter.OrgID != "" {
+		q.Set("org_id", filter.OrgID)
+	}
+	if filter.ApprovalStatus != "" {
+		q.Set("approval_status", filter.ApprovalStatus)
+	}
+	if filter.Limit > 0 {
+		q.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		q.Set("offset", strconv.Itoa(filter.Offset))
+	}
+	path := "/api/agents"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var out AgentList
+	if _, err := c.call(&apiRequest{op: "ListAgents", method: http.MethodGet, path: path, opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAgent returns the agent with registry ID id.
+func (c *Client) GetAgent(id string, opts ...RequestOption) (*Agent, error) {
+	if id == "" {
+		return nil, fmt.Errorf("sandarb: id is required for GetAgent")
+	}
+	var out Agent
+	if _, err := c.call(&apiRequest{op: "GetAgent", method: http.MethodGet, path: "/api/agents/" + url.PathEscape(id), opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SuspendAgent sets the agent's registry status to AgentSuspended. It only records the status:
+// whether a suspended agent's calls are refused depends on the server enforcing it, which the
+// reference backend does not yet do.
+func (c *Client) SuspendAgent(id string, opts ...RequestOption) (*Agent, error) {
+	return c.setAgentStatus("SuspendAgent", id, AgentSuspended, opts)
+}
+
+// RevokeAgent sets the agent's registry status to AgentRevoked. As with SuspendAgent, cutting off
+// the agent's access depends on the server enforcing the status.
+func (c *Client) RevokeAgent(id string, opts ...RequestOption) (*Agent, error) {
+	return c.setAgentStatus("RevokeAgent", id, AgentRevoked, opts)
+}
+
+func (c *Client) setAgentStatus(op, id, status string, opts []RequestOption) (*Agent, error) {
+	if id == "" {
+		return nil, fmt.Errorf("sandarb: id is required for %s", op)
+	}
+	var out Agent
+	if _, err := c.call(&apiRequest{op: op, method: http.MethodPatch, path: "/api/agents/" + url.PathEscape(id), opts: opts}, map[string]string{"status": status}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,92 @@
+package sandarb
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ResourceKind identifies a governed resource type.
+type ResourceKind string
+
+const (
+	ResourceKindPrompt  ResourceKind = "prompt"
+	ResourceKindContext ResourceKind = "context"
+)
+
+// ResourceRef references a prompt or context, optionally pinned to a version.
+type ResourceRef struct {
+	Kind      ResourceKind `json:"kind"`
+	Name      string       `json:"name"`
+	VersionID string       `json:"version_id,omitempty"`
+}
+
+// ApprovalState is the state of an approval request.
+type ApprovalState string
+
+const (
+	ApprovalPending  ApprovalState = "pending"
+	ApprovalApproved ApprovalState = "approved"
+	ApprovalRejected ApprovalState = "rejected"
+)
+
+// Approval is an approval request for a prompt or context version.
+type Approval struct {
+	ID          string        `json:"id"`
+	Resource    ResourceRef   `json:"resource"`
+	State       ApprovalState `json:"state"`
+	SubmittedBy string        `json:"submitted_by,omitempty"`
+	SubmittedAt *time.Time    `json:"submitted_at,omitempty"`
+	DecidedBy   string        `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time    `json:"decided_at,omitempty"`
+	Comment     string        `json:"comment,omitempty"`
+}
+
+// SubmitForApproval submits a prompt or context version for review.
+func (c *Client) SubmitForApproval(ref ResourceRef, comment string, opts ...RequestOption) (*Approval, error) {
+	if ref.Kind != ResourceKindPrompt && ref.Kind != ResourceKindContext {
+		return nil, fmt.Errorf("sandarb: invalid resource kind %q", ref.Kind)
+	}
+	if ref.Name == "" {
+		return nil, fmt.Errorf("sandarb: resource name is required for SubmitForApproval")
+	}
+	body := map[string]interface{}{"resource": ref, "comment": comment}
+	var out Approval
+	if _, err := c.call(&apiRequest{op: "SubmitForApproval", feature: FeatureApprovals, method: http.MethodPost, path: "/api/approvals", opts: opts}, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListPendingApprovals returns approval requests awaiting a decision.
+func (c *Client) ListPendingApprovals(opts ...RequestOption) ([]Approval, error) {
+	var out []Approval
+	path := "/api/approvals?state=" + string(ApprovalPending)
+	if _, err := c.call(&apiRequest{op: "ListPendingApprovals", feature: FeatureApprovals, method: http.MethodGet, path: path, opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Approve approves a pending approval request.
+func (c *Client) Approve(approvalID, comment string, opts ...RequestOption) (*Approval, error) {
+	return c.decideApproval("Approve", approvalID, "approve", comment, opts)
+}
+
+// Reject rejects a pending approval request; reason is recorded in the audit trail.
+func (c *Client) Reject(approvalID, reason string, opts ...RequestOption) (*Approval, error) {
+	return c.decideApproval("Reject", approvalID, "reject", reason, opts)
+}
+
+func (c *Client) decideApproval(op, approvalID, action, comment string, opts []RequestOption) (*Approval, error) {
+	if approvalID == "" {
+		return nil, fmt.Errorf("sandarb: approval id is required for %s", op)
+	}
+	path := "/api/approvals/" + url.PathEscape(approvalID) + "/" + action
+	var out Approval
+	if _, err := c.call(&apiRequest{op: op, feature: FeatureApprovals, method: http.MethodPost, path: path, opts: opts}, map[string]string{"comment": comment}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,137 @@
+package sandarb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// AttachmentRequest describes a file to attach to activity records.
+type AttachmentRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type,omitempty"`
+	// Size is the upload size in bytes, or -1 if unknown.
+	Size    int64  `json:"size"`
+	AgentID string `json:"agent_id,omitempty"`
+	TraceID string `json:"trace_id,omitempty"`
+}
+
+// Attachment is a registered attachment with a signed URL to upload its content to.
+type Attachment struct {
+	ID        string `json:"id"`
+	UploadURL string `json:"upload_url"`
+	// Method is the HTTP method for the upload (PUT if empty).
+	Method string `json:"method,omitempty"`
+	// Headers must be sent with the upload for the signature to validate.
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+}
+
+// UploadProgress is called as upload bytes are sent, with the total upload size.
+type UploadProgress func(sent, total int64)
+
+// CreateAttachment registers an attachment and returns a signed URL to upload its content to.
+// Link it to activity by passing WithAttachments(att.ID) to LogActivity.
+func (c *Client) CreateAttachment(req AttachmentRequest, opts ...RequestOption) (*Attachment, error) {
+	if req.Filename == "" {
+		return nil, fmt.Errorf("sandarb: filename is required for CreateAttachment")
+	}
+	if req.AgentID == "" {
+		req.AgentID = c.AgentID
+	}
+	var out Attachment
+	if _, err := c.call(&apiRequest{op: "CreateAttachment", feature: FeatureAttachments, method: http.MethodPost, path: "/api/attachments", agentID: req.AgentID, traceID: req.TraceID, opts: opts}, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UploadAttachment streams r to att's signed URL. size is the content length, or -1 if unknown,
+// in which case r is read into memory first: signed upload URLs (e.g. S3 PUT) require a
+// Content-Length and reject chunked bodies. The signed URL carries its own authorization, so the
+// client's API key is not sent. Large uploads are not bound by HTTPClient.Timeout; use
+// WithRequestTimeout or WithContext to limit them. Under WithDryRun nothing is uploaded and r is
+// not read.
+func (c *Client) UploadAttachment(att *Attachment, r io.Reader, size int64, progress UploadProgress, opts ...RequestOption) error {
+	if att == nil {
+		return fmt.Errorf("sandarb: attachment is required for UploadAttachment")
+	}
+	if c.dryRun != nil {
+		return nil
+	}
+	if att.UploadURL == "" {
+		return fmt.Errorf("sandarb: attachment with an upload URL is required for UploadAttachment")
+	}
+	if size < 0 {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("sandarb: read attachment: %w", err)
+		}
+		r, size = bytes.NewReader(b), int64(len(b))
+	}
+	ro := newRequestOptions(opts)
+	ctx, cancel := ro.ctx, context.CancelFunc(func() {})
+	if ro.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, ro.timeout)
+	}
+	defer cancel()
+	method := att.Method
+	if method == "" {
+		method = http.MethodPut
+	}
+	body := io.Reader(http.NoBody)
+	if size > 0 {
+		body = r
+		if progress != nil {
+			body = &progressReader{r: r, total: size, fn: progress}
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, att.UploadURL, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	for k, v := range att.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, vs := range ro.header {
+		req.Header[k] = vs
+	}
+	hc := *c.httpClient()
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return &SandarbError{Message: fmt.Sprintf("attachment upload failed: %s", resp.Status), StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+// WithAttachments links attachment IDs to the activity being logged (metadata "attachments").
+// It only affects LogActivity and Session.LogTurn.
+func WithAttachments(ids ...string) RequestOption {
+	return func(o *requestOptions) { o.attachments = append(o.attachments, ids...) }
+}
+
+type progressReader struct {
+	r     io.Reader
+	sent  int64
+	total int64
+	fn    UploadProgress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.fn(p.sent, p.total)
+	}
+	return n, err
+}
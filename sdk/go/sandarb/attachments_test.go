@@ -0,0 +1,67 @@
+package sandarb
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUploadAttachmentSendsContentLength(t *testing.T) {
+	type upload struct {
+		length  int64
+		chunked bool
+		body    string
+	}
+	var got upload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = upload{length: r.ContentLength, chunked: len(r.TransferEncoding) > 0, body: string(b)}
+	}))
+	defer srv.Close()
+
+	c := NewClient()
+	att := &Attachment{ID: "a1", UploadURL: srv.URL + "/upload"}
+	tests := []struct {
+		name    string
+		content string
+		size    int64
+	}{
+		{"known size", "hello", 5},
+		{"unknown size", "hello world", -1},
+		{"empty", "", 0},
+	}
+	for _, tt := range tests {
+		var sent, total int64
+		progress := func(s, t int64) { sent, total = s, t }
+		if err := c.UploadAttachment(att, strings.NewReader(tt.content), tt.size, progress); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		want := int64(len(tt.content))
+		if got.chunked || got.length != want || got.body != tt.content {
+			t.Errorf("%s: server saw %+v, want Content-Length %d and body %q", tt.name, got, want, tt.content)
+		}
+		if want > 0 && (sent != want || total != want) {
+			t.Errorf("%s: progress %d/%d, want %d/%d", tt.name, sent, total, want, want)
+		}
+	}
+}
+
+func TestUploadAttachmentDryRun(t *testing.T) {
+	c := NewClient(WithDryRun())
+	att, err := c.CreateAttachment(AttachmentRequest{Filename: "report.pdf", Size: 3, AgentID: "agent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := strings.NewReader("pdf")
+	if err := c.UploadAttachment(att, r, 3, nil); err != nil {
+		t.Fatalf("UploadAttachment under dry run: %v", err)
+	}
+	if r.Len() != 3 {
+		t.Error("dry-run upload read the content")
+	}
+	if w := c.DryRun().Writes(); len(w) != 1 || w[0].Op != "CreateAttachment" {
+		t.Errorf("recorded writes = %+v", w)
+	}
+}
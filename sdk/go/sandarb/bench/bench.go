@@ -0,0 +1,254 @@
+// Package bench records per-method Sandarb client latencies and reports them against latency budgets,
+// so teams can show the governance layer stays within agreed SLOs.
+//
+//	rec := bench.NewRecorder(0)
+//	client := sandarb.NewClient(bench.WithLatencyRecorder(rec))
+//	// ... agent startup: GetContext, GetPrompt, ...
+//	report := rec.Report(bench.Budget{Method: "GetContext", Percentile: 99, Max: 150 * time.Millisecond})
+//	report.WriteTo(os.Stderr)
+//	if !report.OK() { ... }
+package bench
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb"
+)
+
+// DefaultWindow is the number of most recent samples kept per method.
+const DefaultWindow = 1024
+
+// Recorder implements sandarb.MetricsRecorder, keeping a sliding window of request latencies per SDK method.
+type Recorder struct {
+	window int
+
+	mu      sync.Mutex
+	methods map[string]*series
+}
+
+type series struct {
+	samples []time.Duration // ring buffer
+	next    int
+	count   int64
+	errors  int64
+	retries int64
+	hits    int64
+}
+
+// NewRecorder returns a Recorder keeping the last window samples per method (DefaultWindow if <= 0).
+func NewRecorder(window int) *Recorder {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Recorder{window: window, methods: make(map[string]*series)}
+}
+
+// WithLatencyRecorder instruments the client with r, alongside any MetricsRecorder already set.
+func WithLatencyRecorder(r *Recorder) sandarb.ClientOption {
+	return func(c *sandarb.Client) {
+		if c.Metrics != nil {
+			c.Metrics = sandarb.MultiMetrics(c.Metrics, r)
+		} else {
+			c.Metrics = r
+		}
+	}
+}
+
+func (r *Recorder) series(method string) *series {
+	s, ok := r.methods[method]
+	if !ok {
+		s = &series{}
+		r.methods[method] = s
+	}
+	return s
+}
+
+// ObserveRequest implements sandarb.MetricsRecorder. Transport errors and 5xx count as errors.
+func (r *Recorder) ObserveRequest(method string, status int, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.series(method)
+	if len(s.samples) < r.window {
+		s.samples = append(s.samples, d)
+	} else {
+		s.samples[s.next] = d
+		s.next = (s.next + 1) % r.window
+	}
+	s.count++
+	if status == 0 || status >= 500 {
+		s.errors++
+	}
+}
+
+// CacheHit implements sandarb.MetricsRecorder.
+func (r *Recorder) CacheHit(method string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.series(method).hits++
+}
+
+// Retry implements sandarb.MetricsRecorder.
+func (r *Recorder) Retry(method string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.series(method).retries++
+}
+
+// Stats summarizes one method's latencies over the recorder's window.
+type Stats struct {
+	Method                   string
+	Count                    int64 // requests observed in total (not only in the window)
+	Errors, Retries, Hits    int64
+	P50, P95, P99, Max, Mean time.Duration
+
+	sorted []time.Duration // the window's samples, ascending
+}
+
+// Percentile returns the nearest-rank p-th percentile (0 < p <= 100) of the window. p is clamped
+// to that range, so Percentile(0) is the fastest sample and Percentile(100) is Max.
+func (s Stats) Percentile(p float64) time.Duration {
+	if len(s.sorted) == 0 {
+		return 0
+	}
+	return rank(s.sorted, p)
+}
+
+// Stats returns latency statistics for every method observed, sorted by method name.
+func (r *Recorder) Stats() []Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Stats, 0, len(r.methods))
+	for m, s := range r.methods {
+		st := Stats{Method: m, Count: s.count, Errors: s.errors, Retries: s.retries, Hits: s.hits}
+		if n := len(s.samples); n > 0 {
+			sorted := append([]time.Duration(nil), s.samples...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			var sum time.Duration
+			for _, d := range sorted {
+				sum += d
+			}
+			st.P50, st.P95, st.P99 = rank(sorted, 50), rank(sorted, 95), rank(sorted, 99)
+			st.Max, st.Mean = sorted[n-1], sum/time.Duration(n)
+			st.sorted = sorted
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Method < out[j].Method })
+	return out
+}
+
+// rank returns the nearest-rank percentile of sorted samples.
+func rank(sorted []time.Duration, p float64) time.Duration {
+	if math.IsNaN(p) || p <= 0 {
+		return sorted[0]
+	}
+	if p >= 100 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[int(math.Ceil(p/100*float64(len(sorted))))-1]
+}
+
+// Reset discards all recorded samples and counters.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.methods = make(map[string]*series)
+}
+
+// Budget is a latency SLO: the Percentile (e.g. 99) latency of Method must not exceed Max.
+type Budget struct {
+	Method     string
+	Percentile float64
+	Max        time.Duration
+}
+
+// BudgetCheck is the outcome of one Budget.
+type BudgetCheck struct {
+	Budget
+	Observed time.Duration
+	// Missing is true when no requests were observed for the method.
+	Missing bool
+	OK      bool
+}
+
+// Report is a latency budget report: per-method statistics and budget outcomes.
+type Report struct {
+	Stats  []Stats
+	Checks []BudgetCheck
+}
+
+// OK reports whether every budget was met.
+func (rep *Report) OK() bool {
+	for _, c := range rep.Checks {
+		if !c.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Report evaluates budgets against the recorded latencies. A budget for a method with no
+// observations fails, so a misspelled method name can't silently pass.
+func (r *Recorder) Report(budgets ...Budget) *Report {
+	rep := &Report{Stats: r.Stats()}
+	byMethod := make(map[string]Stats, len(rep.Stats))
+	for _, s := range rep.Stats {
+		byMethod[s.Method] = s
+	}
+	for _, b := range budgets {
+		chk := BudgetCheck{Budget: b}
+		if s, ok := byMethod[b.Method]; ok && s.Count > 0 {
+			chk.Observed = s.Percentile(b.Percentile)
+			chk.OK = chk.Observed <= b.Max
+		} else {
+			chk.Missing = true
+		}
+		rep.Checks = append(rep.Checks, chk)
+	}
+	return rep
+}
+
+// WriteTo writes the report as aligned text tables.
+func (rep *Report) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tCOUNT\tERRORS\tRETRIES\tCACHE HITS\tP50\tP95\tP99\tMAX")
+	for _, s := range rep.Stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%s\t%s\t%s\n", s.Method, s.Count, s.Errors, s.Retries, s.Hits, s.P50, s.P95, s.P99, s.Max)
+	}
+	if len(rep.Checks) > 0 {
+		fmt.Fprintln(tw, "\nBUDGET\tOBSERVED\tLIMIT\tRESULT")
+		for _, c := range rep.Checks {
+			result, observed := "ok", c.Observed.String()
+			if c.Missing {
+				result, observed = "FAIL (no samples)", "-"
+			} else if !c.OK {
+				result = "FAIL"
+			}
+			fmt.Fprintf(tw, "%s p%g\t%s\t%s\t%s\n", c.Method, c.Percentile, observed, c.Max, result)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return 0, err
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Run calls fn iterations times (e.g. an agent's startup fetches) so the recorder has enough
+// samples for stable percentiles. It stops at the first error.
+func Run(iterations int, fn func() error) error {
+	for i := 0; i < iterations; i++ {
+		if err := fn(); err != nil {
+			return fmt.Errorf("sandarb: bench iteration %d: %w", i, err)
+		}
+	}
+	return nil
+}
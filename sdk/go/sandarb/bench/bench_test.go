@@ -0,0 +1,87 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func ms(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+func TestPercentileNearestRank(t *testing.T) {
+	r := NewRecorder(0)
+	for i := 100; i >= 1; i-- {
+		r.ObserveRequest("GetContext", 200, ms(i))
+	}
+	st := r.Stats()
+	if len(st) != 1 {
+		t.Fatalf("Stats = %+v", st)
+	}
+	s := st[0]
+	if s.P50 != ms(50) || s.P95 != ms(95) || s.P99 != ms(99) || s.Max != ms(100) {
+		t.Errorf("P50/P95/P99/Max = %v/%v/%v/%v", s.P50, s.P95, s.P99, s.Max)
+	}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, ms(1)}, {1, ms(1)}, {25, ms(25)}, {50, ms(50)}, {75, ms(75)}, {90, ms(90)},
+		{99.5, ms(100)}, {99.9, ms(100)}, {100, ms(100)}, {150, ms(100)},
+	}
+	for _, tt := range tests {
+		if got := s.Percentile(tt.p); got != tt.want {
+			t.Errorf("Percentile(%g) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := (Stats{}).Percentile(90); got != 0 {
+		t.Errorf("Percentile with no samples = %v, want 0", got)
+	}
+}
+
+func TestRecorderKeepsWindow(t *testing.T) {
+	r := NewRecorder(4)
+	for i := 1; i <= 10; i++ {
+		status := 200
+		if i%5 == 0 {
+			status = 503
+		}
+		r.ObserveRequest("GetPrompt", status, ms(i))
+	}
+	s := r.Stats()[0]
+	// Only 7..10 remain in the window; Count and Errors cover every request.
+	if s.Count != 10 || s.Errors != 2 {
+		t.Errorf("Count/Errors = %d/%d, want 10/2", s.Count, s.Errors)
+	}
+	if s.Percentile(1) != ms(7) || s.Max != ms(10) || s.Mean != ms(8)+ms(1)/2 {
+		t.Errorf("min/Max/Mean = %v/%v/%v, want 7ms/10ms/8.5ms", s.Percentile(1), s.Max, s.Mean)
+	}
+}
+
+func TestReportBudgets(t *testing.T) {
+	r := NewRecorder(0)
+	for i := 1; i <= 100; i++ {
+		r.ObserveRequest("GetContext", 200, ms(i))
+	}
+
+	ok := r.Report(
+		Budget{Method: "GetContext", Percentile: 90, Max: ms(90)},
+		Budget{Method: "GetContext", Percentile: 99, Max: ms(100)},
+	)
+	if !ok.OK() {
+		t.Errorf("budgets within limits failed: %+v", ok.Checks)
+	}
+
+	over := r.Report(Budget{Method: "GetContext", Percentile: 90, Max: ms(89)})
+	if over.OK() || over.Checks[0].Observed != ms(90) {
+		t.Errorf("over-budget check = %+v", over.Checks[0])
+	}
+
+	missing := r.Report(Budget{Method: "GetContxt", Percentile: 99, Max: time.Second})
+	if missing.OK() || !missing.Checks[0].Missing {
+		t.Errorf("budget for unobserved method = %+v, want a missing failure", missing.Checks[0])
+	}
+	var b strings.Builder
+	if _, err := missing.WriteTo(&b); err != nil || !strings.Contains(b.String(), "FAIL (no samples)") {
+		t.Errorf("WriteTo = %q, %v", b.String(), err)
+	}
+}
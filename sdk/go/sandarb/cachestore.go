@@ -0,0 +1,182 @@
+package sandarb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// CacheStore persists cached prompts and contexts outside the process, so short-lived processes
+// (serverless functions, CLI runs) start warm and instances can share one cache. Keys are
+// opaque; values are the SDK's own encoding. The sandarb/stores package has file and Redis
+// implementations.
+type CacheStore interface {
+	// Get returns the value stored under key; ok is false when there is none or it expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// CacheStoreOptions configures WithCacheStore.
+type CacheStoreOptions struct {
+	// PromptTTL is how long compiled prompts are reused (default 5m).
+	PromptTTL time.Duration
+	// ContextTTL is how long contexts are reused (default 1m). Negative disables context caching.
+	ContextTTL time.Duration
+	// Prefix namespaces keys, e.g. per environment when a store is shared (default "sandarb:").
+	Prefix string
+	// Timeout bounds each store operation (default 250ms), so a slow store degrades to a miss.
+	Timeout time.Duration
+}
+
+// WithCacheStore caches GetPrompt, PreviewPrompt, and GetContext results in store, behind the
+// in-memory prompt cache when WithPromptCache is also set. Entries are keyed by agent, name, and
+// (for prompts) version and variables. Contexts are stored as served, so encrypted payloads stay
+// encrypted at rest and are decrypted on each read. Store errors are treated as misses. Cache
+// hits are not seen by the server, so they are absent from its access log; they are reported via
+// MetricsRecorder.CacheHit and marked with the result's Cached field.
+func WithCacheStore(store CacheStore, opts CacheStoreOptions) ClientOption {
+	if opts.PromptTTL <= 0 {
+		opts.PromptTTL = 5 * time.Minute
+	}
+	if opts.ContextTTL == 0 {
+		opts.ContextTTL = time.Minute
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "sandarb:"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 250 * time.Millisecond
+	}
+	return func(c *Client) { c.store = &cacheStore{store: store, opts: opts} }
+}
+
+type cacheStore struct {
+	store CacheStore
+	opts  CacheStoreOptions
+}
+
+// storedEntry is the persisted form of a cached result.
+type storedEntry struct {
+	Expires time.Time        `json:"expires"`
+	Prompt  *GetPromptResult `json:"prompt,omitempty"`
+	Context *storedContext   `json:"context,omitempty"`
+	Meta    storedMeta       `json:"meta"`
+}
+
+// storedContext is a context as served, before decryption and migration.
+type storedContext struct {
+	Content          map[string]interface{} `json:"content"`
+	ContextVersionID *string                `json:"context_version_id,omitempty"`
+	SchemaVersion    int                    `json:"schema_version,omitempty"`
+	ServedBy         string                 `json:"served_by,omitempty"`
+	Region           string                 `json:"region,omitempty"`
+}
+
+type storedMeta struct {
+	RequestID string `json:"request_id,omitempty"`
+	Region    string `json:"region,omitempty"`
+	ServedBy  string `json:"served_by,omitempty"`
+}
+
+func (m storedMeta) responseMeta() ResponseMeta {
+	return ResponseMeta{RequestID: m.RequestID, RateLimit: -1, RateLimitRemaining: -1, Region: m.Region, ServedBy: m.ServedBy}
+}
+
+func newStoredMeta(m ResponseMeta) storedMeta {
+	return storedMeta{RequestID: m.RequestID, Region: m.Region, ServedBy: m.ServedBy}
+}
+
+// usable reports whether a request may be served from or written to the store; requests with
+// custom headers or query parameters may get a different response.
+func (cs *cacheStore) usable(c *Client, opts []RequestOption) bool {
+	if cs == nil || c.dryRun != nil {
+		return false
+	}
+	ro := newRequestOptions(opts)
+	return ro.header == nil && ro.query == nil
+}
+
+func (cs *cacheStore) key(kind, id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return cs.opts.Prefix + kind + ":" + hex.EncodeToString(sum[:])
+}
+
+func (cs *cacheStore) get(opts []RequestOption, key string) (*storedEntry, bool) {
+	ctx, cancel := context.WithTimeout(newRequestOptions(opts).ctx, cs.opts.Timeout)
+	defer cancel()
+	b, ok, err := cs.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var e storedEntry
+	if json.Unmarshal(b, &e) != nil || time.Now().After(e.Expires) {
+		return nil, false
+	}
+	return &e, true
+}
+
+func (cs *cacheStore) put(opts []RequestOption, key string, e *storedEntry, ttl time.Duration) {
+	e.Expires = time.Now().Add(ttl)
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(newRequestOptions(opts).ctx, cs.opts.Timeout)
+	defer cancel()
+	cs.store.Set(ctx, key, b, ttl)
+}
+
+// storedPrompt returns a prompt from the store under the prompt cache key k.
+func (c *Client) storedPrompt(k string, opts []RequestOption) (*GetPromptResult, bool) {
+	if !c.store.usable(c, opts) {
+		return nil, false
+	}
+	e, ok := c.store.get(opts, c.store.key("prompt", k))
+	if !ok || e.Prompt == nil {
+		return nil, false
+	}
+	r := e.Prompt
+	r.meta = e.Meta.responseMeta()
+	return r, true
+}
+
+func (c *Client) storePrompt(k string, r *GetPromptResult, opts []RequestOption) {
+	if !c.store.usable(c, opts) {
+		return
+	}
+	p := *r
+	p.Cached, p.Tokens = false, nil
+	c.store.put(opts, c.store.key("prompt", k), &storedEntry{Prompt: &p, Meta: newStoredMeta(r.meta)}, c.store.opts.PromptTTL)
+}
+
+// storedContext returns a context from the store, decrypted and migrated as if just served.
+func (c *Client) storedContext(ctxName, agentID string, opts []RequestOption) (*GetContextResult, bool) {
+	if !c.store.usable(c, opts) || c.store.opts.ContextTTL < 0 {
+		return nil, false
+	}
+	e, ok := c.store.get(opts, c.store.key("context", agentID+"\x00"+ctxName))
+	if !ok || e.Context == nil {
+		return nil, false
+	}
+	sc := e.Context
+	r, err := c.contextResult(ctxName, sc.Content, sc.ContextVersionID, sc.SchemaVersion, sc.ServedBy)
+	if err != nil {
+		return nil, false
+	}
+	r.Region, r.Cached, r.meta = sc.Region, true, e.Meta.responseMeta()
+	return r, true
+}
+
+func (c *Client) storeContext(ctxName, agentID string, r *GetContextResult, opts []RequestOption) {
+	if !c.store.usable(c, opts) || c.store.opts.ContextTTL < 0 {
+		return
+	}
+	sc := &storedContext{Content: r.raw, ContextVersionID: r.ContextVersionID, SchemaVersion: r.SchemaVersion, ServedBy: r.ServedBy, Region: r.Region}
+	if r.MigratedFrom != 0 {
+		sc.SchemaVersion = r.MigratedFrom
+	}
+	c.store.put(opts, c.store.key("context", agentID+"\x00"+ctxName), &storedEntry{Context: sc, Meta: newStoredMeta(r.meta)}, c.store.opts.ContextTTL)
+}
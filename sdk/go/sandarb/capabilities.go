@@ -0,0 +1,180 @@
+package sandarb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Feature names an optional server capability.
+type Feature string
+
+const (
+	FeatureBatchContexts   Feature = "contexts.batch"
+	FeatureContextLineage  Feature = "contexts.lineage"
+	FeatureSchemas         Feature = "schemas"
+	FeatureApprovals       Feature = "approvals"
+	FeatureRoles           Feature = "roles"
+	FeaturePromptPreview   Feature = "prompts.preview"
+	FeatureBulkActivity    Feature = "audit.bulk"
+	FeatureContextMarkdown Feature = "inject.markdown"
+	FeaturePromptManifest  Feature = "prompts.manifest"
+	FeatureModeration      Feature = "moderation"
+	FeatureTools           Feature = "tools"
+	FeatureAttachments     Feature = "attachments"
+	FeatureAuditExport     Feature = "audit.export"
+	FeatureErasure         Feature = "audit.erasure"
+	FeatureChains          Feature = "chains"
+	FeatureFlags           Feature = "flags"
+	FeaturePolicyBundles   Feature = "policies.bundles"
+	FeatureSubscriptions   Feature = "agents.subscriptions"
+	FeatureIncidents       Feature = "incidents"
+)
+
+// capabilitiesTTL is how long discovered capabilities are reused before being refetched.
+const capabilitiesTTL = 10 * time.Minute
+
+// capabilitiesFailureTTL is how long a failed discovery is reused, so an unreachable server
+// does not cost every call an extra request.
+const capabilitiesFailureTTL = 30 * time.Second
+
+// ErrUnsupportedFeature is matched (via errors.Is) by errors for features the server does not offer.
+var ErrUnsupportedFeature = errors.New("sandarb: feature not supported by server")
+
+// UnsupportedFeatureError reports a call to a feature the connected server does not offer.
+type UnsupportedFeatureError struct {
+	Feature    Feature
+	APIVersion string
+}
+
+func (e *UnsupportedFeatureError) Error() string {
+	return fmt.Sprintf("sandarb: feature %q not supported by server (api version %s)", e.Feature, e.APIVersion)
+}
+
+// Is makes errors.Is(err, ErrUnsupportedFeature) true.
+func (e *UnsupportedFeatureError) Is(target error) bool { return target == ErrUnsupportedFeature }
+
+// Capabilities describes the server's API version and optional features.
+type Capabilities struct {
+	APIVersion    string    `json:"api_version"`
+	ServerVersion string    `json:"server_version,omitempty"`
+	Features      []Feature `json:"features"`
+	// Legacy is true when the server predates capability discovery; only the core API is assumed.
+	Legacy bool `json:"-"`
+}
+
+// Supports reports whether the server offers f.
+func (caps *Capabilities) Supports(f Feature) bool {
+	for _, x := range caps.Features {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
+
+type capabilitiesCache struct {
+	mu       sync.Mutex
+	caps     *Capabilities
+	fetched  time.Time
+	err      error
+	failedAt time.Time
+	flight   flightGroup
+}
+
+func (caps *Capabilities) clone() *Capabilities {
+	cp := *caps
+	cp.Features = append([]Feature(nil), caps.Features...)
+	return &cp
+}
+
+// Capabilities returns the server's supported API version and features. Results are cached,
+// and failures other than a missing endpoint are cached briefly; servers without the discovery
+// endpoint are reported as Legacy with no optional features. Concurrent callers share one fetch.
+func (c *Client) Capabilities(opts ...RequestOption) (*Capabilities, error) {
+	c.caps.mu.Lock()
+	if c.caps.caps != nil && time.Since(c.caps.fetched) < capabilitiesTTL {
+		caps := c.caps.caps.clone()
+		c.caps.mu.Unlock()
+		return caps, nil
+	}
+	if c.caps.err != nil && time.Since(c.caps.failedAt) < capabilitiesFailureTTL {
+		err := c.caps.err
+		c.caps.mu.Unlock()
+		return nil, err
+	}
+	c.caps.mu.Unlock()
+
+	fetch := func() (interface{}, error) {
+		var caps Capabilities
+		_, err := c.call(&apiRequest{op: "Capabilities", method: http.MethodGet, path: "/api/capabilities", opts: append(append([]RequestOption(nil), opts...), optional())}, nil, &caps)
+		var se *SandarbError
+		if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+			caps, err = Capabilities{APIVersion: "1", Legacy: true}, nil
+		}
+		c.caps.mu.Lock()
+		defer c.caps.mu.Unlock()
+		if err != nil {
+			// A caller's own canceled context says nothing about the server.
+			if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+				c.caps.err, c.caps.failedAt = err, time.Now()
+			}
+			return nil, err
+		}
+		c.caps.caps, c.caps.fetched, c.caps.err = &caps, time.Now(), nil
+		return &caps, nil
+	}
+	ctx := newRequestOptions(opts).ctx
+	v, err, shared := c.caps.flight.do(ctx, "", fetch)
+	if shared && err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) && ctx.Err() == nil {
+		v, err = fetch()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Capabilities).clone(), nil
+}
+
+// discoveryOptions carries a call's context, deadline, trace ID and headers over to the
+// capability lookup it triggers, without its query parameters or response metadata.
+func discoveryOptions(opts []RequestOption) []RequestOption {
+	ro := newRequestOptions(opts)
+	out := []RequestOption{WithContext(ro.ctx)}
+	if ro.timeout > 0 {
+		out = append(out, WithRequestTimeout(ro.timeout))
+	}
+	if ro.traceID != "" {
+		out = append(out, WithTraceID(ro.traceID))
+	}
+	for k, vs := range ro.header {
+		for _, v := range vs {
+			out = append(out, WithHeader(k, v))
+		}
+	}
+	return out
+}
+
+// supports reports whether the server offers f, treating discovery failures as unsupported.
+func (c *Client) supports(f Feature, opts []RequestOption) bool {
+	if c.dryRun != nil {
+		return true
+	}
+	caps, err := c.Capabilities(discoveryOptions(opts)...)
+	return err == nil && caps.Supports(f)
+}
+
+// requireFeature returns an UnsupportedFeatureError if the server is known not to offer f.
+// Discovery failures are not fatal: the call proceeds and the server decides.
+func (c *Client) requireFeature(f Feature, opts []RequestOption) error {
+	if c.dryRun != nil {
+		return nil
+	}
+	caps, err := c.Capabilities(discoveryOptions(opts)...)
+	if err != nil || caps.Supports(f) {
+		return nil
+	}
+	return &UnsupportedFeatureError{Feature: f, APIVersion: caps.APIVersion}
+}
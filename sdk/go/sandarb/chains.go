@@ -0,0 +1,142 @@
+package sandarb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ChainStep is one prompt in a chain.
+type ChainStep struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+	// Model overrides the prompt's configured model for this step, if set.
+	Model string `json:"model,omitempty"`
+	// Variables maps each prompt variable to its source: "input.<key>" for a chain input, or
+	// "steps.<name>" for the output of an earlier step.
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// Chain is a centrally defined, versioned sequence of prompt steps.
+type Chain struct {
+	ID      string      `json:"id"`
+	Name    string      `json:"name"`
+	Version int         `json:"version"`
+	Steps   []ChainStep `json:"steps"`
+}
+
+// GetChain returns the current approved version of a prompt chain.
+func (c *Client) GetChain(name string, opts ...RequestOption) (*Chain, error) {
+	if name == "" {
+		return nil, fmt.Errorf("sandarb: chain name is required for GetChain")
+	}
+	var out Chain
+	if _, err := c.call(&apiRequest{op: "GetChain", feature: FeatureChains, method: http.MethodGet, path: "/api/chains?name=" + url.QueryEscape(name), opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ChainExecutor runs one compiled step against a model and returns its output. model is the
+// step's Model, else the prompt's configured model, else "".
+type ChainExecutor interface {
+	ExecuteStep(ctx context.Context, step ChainStep, prompt *GetPromptResult, model string) (string, error)
+}
+
+// ChainExecutorFunc adapts a function to ChainExecutor.
+type ChainExecutorFunc func(ctx context.Context, step ChainStep, prompt *GetPromptResult, model string) (string, error)
+
+// ExecuteStep implements ChainExecutor.
+func (f ChainExecutorFunc) ExecuteStep(ctx context.Context, step ChainStep, prompt *GetPromptResult, model string) (string, error) {
+	return f(ctx, step, prompt, model)
+}
+
+// ChainStepResult records one executed step.
+type ChainStepResult struct {
+	Step          string
+	PromptVersion int
+	Model         string
+	Output        string
+	Duration      time.Duration
+}
+
+// ChainResult is the outcome of RunChain. Output is the last step's output.
+type ChainResult struct {
+	Output string
+	Steps  []ChainStepResult
+}
+
+// RunChain executes chain's steps in order under the session: each step's prompt is fetched
+// with GetPrompt, run through exec, and logged as an activity with chain and step metadata. The
+// context from WithContext in opts is passed to exec. It stops at the first failing step.
+func (s *Session) RunChain(chain *Chain, input map[string]interface{}, exec ChainExecutor, opts ...RequestOption) (*ChainResult, error) {
+	ctx := newRequestOptions(opts).ctx
+	outputs := make(map[string]string, len(chain.Steps))
+	res := &ChainResult{}
+	for i, step := range chain.Steps {
+		vars, err := chainVariables(step, input, outputs)
+		if err != nil {
+			return res, fmt.Errorf("sandarb: chain %q step %q: %w", chain.Name, step.Name, err)
+		}
+		prompt, err := s.GetPrompt(step.Prompt, vars, opts...)
+		if err != nil {
+			return res, fmt.Errorf("sandarb: chain %q step %q: %w", chain.Name, step.Name, err)
+		}
+		model := step.Model
+		if model == "" && prompt.Model != nil {
+			model = *prompt.Model
+		}
+		start := time.Now()
+		out, err := exec.ExecuteStep(ctx, step, prompt, model)
+		if err != nil {
+			return res, fmt.Errorf("sandarb: chain %q step %q: %w", chain.Name, step.Name, err)
+		}
+		sr := ChainStepResult{Step: step.Name, PromptVersion: prompt.Version, Model: model, Output: out, Duration: time.Since(start)}
+		res.Steps = append(res.Steps, sr)
+		res.Output = out
+		outputs[step.Name] = out
+		meta := s.Metadata()
+		meta["chain"], meta["chain_version"] = chain.Name, chain.Version
+		meta["step"], meta["step_index"] = step.Name, i
+		meta["prompt"], meta["prompt_version"], meta["model"] = step.Prompt, prompt.Version, model
+		meta["duration_ms"] = sr.Duration.Milliseconds()
+		err = s.client.logActivity(&ActivityRecord{
+			AgentID:  s.agentID,
+			TraceID:  s.traceID,
+			Inputs:   vars,
+			Outputs:  map[string]interface{}{"output": out},
+			Metadata: meta,
+		}, opts)
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// chainVariables resolves a step's variable mapping against chain inputs and earlier outputs.
+func chainVariables(step ChainStep, input map[string]interface{}, outputs map[string]string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{}, len(step.Variables))
+	for name, src := range step.Variables {
+		switch {
+		case strings.HasPrefix(src, "input."):
+			v, ok := input[strings.TrimPrefix(src, "input.")]
+			if !ok {
+				return nil, fmt.Errorf("variable %q: missing chain input %q", name, src)
+			}
+			vars[name] = v
+		case strings.HasPrefix(src, "steps."):
+			v, ok := outputs[strings.TrimPrefix(src, "steps.")]
+			if !ok {
+				return nil, fmt.Errorf("variable %q: %q has not run", name, src)
+			}
+			vars[name] = v
+		default:
+			return nil, fmt.Errorf("variable %q: unknown source %q", name, src)
+		}
+	}
+	return vars, nil
+}
@@ -2,15 +2,17 @@ package sandarb
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"sync"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 // SandarbError is returned when an API call fails.
@@ -18,6 +20,8 @@ type SandarbError struct {
 	Message    string
 	StatusCode int
 	Body       string
+	// Meta is the failed response's metadata (request ID, rate limits, Retry-After).
+	Meta ResponseMeta
 }
 
 func (e *SandarbError) Error() string {
@@ -29,6 +33,53 @@ type Client struct {
 	BaseURL    string
 	APIKey     string
 	HTTPClient *http.Client
+	// AgentID is the default agent ID used when a call is given none.
+	AgentID string
+	// KeyProvider decrypts encrypted context payloads; nil disables decryption.
+	KeyProvider KeyProvider
+	// FallbackURLs are secondary base URLs used when BaseURL is unhealthy.
+	FallbackURLs []string
+	// Metrics receives request, cache, and retry events; nil disables instrumentation.
+	Metrics MetricsRecorder
+
+	failoverThreshold   int
+	healthCheckInterval time.Duration
+	poolMu              sync.Mutex
+	pool                *endpointPool
+	spool               *Spool
+	replaying           int32
+	sampler             *sampler
+	payloadLimit        *payloadLimit
+	schemas             sync.Map // schemaCacheKey -> cachedSchema
+	caps                capabilitiesCache
+	manifests           sync.Map // prompt name -> cachedManifest
+	skipVarValidation   bool
+	region              string
+	regionEndpoints     map[string][]string
+	strictRegion        bool
+	deadlineMargin      time.Duration
+	promptCache         *promptCache
+	store               *cacheStore
+	migrations          migrationRegistry
+	dryRun              *DryRun
+	sink                ActivitySink
+	sinkMode            SinkMode
+	secrets             *secretScanner
+	credentials         func(agentID string) string
+	flags               sync.Map // agent ID -> *flagState
+	flagTTL             time.Duration
+	flagStreaming       bool
+	life                lifecycle
+	signer              *requestSigner
+	tokenizer           Tokenizer
+	modelWindows        map[string]int
+	onTokenBudget       func(*TokenBudgetError)
+	inflight            flightGroup
+	noCoalesce          bool
+	userAgent           string
+	noTelemetry         bool
+	errorHandler        func(error, RequestInfo)
+	incidents           incidentLog
 }
 
 // ClientOption configures the Client.
@@ -44,6 +95,18 @@ func WithAPIKey(key string) ClientOption {
 	return func(c *Client) { c.APIKey = key }
 }
 
+// WithCredentialResolver authenticates each request with the key resolve returns for the request's
+// agent ID, so one client can serve agents with distinct service account keys. Requests without
+// an agent ID, or for which resolve returns "", use APIKey.
+func WithCredentialResolver(resolve func(agentID string) string) ClientOption {
+	return func(c *Client) { c.credentials = resolve }
+}
+
+// CredentialMap returns a resolver for WithCredentialResolver backed by a fixed agent ID to key map.
+func CredentialMap(keys map[string]string) func(agentID string) string {
+	return func(agentID string) string { return keys[agentID] }
+}
+
 // WithTimeout sets the HTTP client timeout.
 func WithTimeout(d time.Duration) ClientOption {
 	return func(c *Client) {
@@ -54,9 +117,37 @@ func WithTimeout(d time.Duration) ClientOption {
 	}
 }
 
-// NewClient creates a Sandarb client. API key defaults to SANDARB_API_KEY env.
+// WithAgentID sets the default agent ID used when a call is given none.
+func WithAgentID(agentID string) ClientOption {
+	return func(c *Client) { c.AgentID = agentID }
+}
+
+// Config is explicit client configuration for environments without process environment
+// variables (e.g. js/wasm edge workers). Empty fields use the same defaults as NewClient.
+type Config struct {
+	BaseURL string
+	APIKey  string
+	AgentID string
+	Timeout time.Duration
+	// DisableTelemetry omits SDK, Go version, and platform headers (see WithoutTelemetryHeaders).
+	DisableTelemetry bool
+}
+
+// NewClient creates a Sandarb client. Base URL, API key and default agent ID default to the
+// SANDARB_URL, SANDARB_API_KEY and SANDARB_AGENT_ID env vars; SANDARB_DISABLE_TELEMETRY=1
+// disables telemetry headers.
 func NewClient(opts ...ClientOption) *Client {
-	base := os.Getenv("SANDARB_URL")
+	return NewClientFromConfig(Config{
+		BaseURL:          os.Getenv("SANDARB_URL"),
+		APIKey:           os.Getenv("SANDARB_API_KEY"),
+		AgentID:          os.Getenv("SANDARB_AGENT_ID"),
+		DisableTelemetry: os.Getenv("SANDARB_DISABLE_TELEMETRY") == "1",
+	}, opts...)
+}
+
+// NewClientFromConfig creates a Sandarb client from cfg without reading the environment.
+func NewClientFromConfig(cfg Config, opts ...ClientOption) *Client {
+	base := cfg.BaseURL
 	if base == "" {
 		base = "https://api.sandarb.ai"
 	}
@@ -64,10 +155,18 @@ func NewClient(opts ...ClientOption) *Client {
 	if len(base) > 0 && base[len(base)-1] == '/' {
 		base = base[:len(base)-1]
 	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
 	c := &Client{
 		BaseURL:    base,
-		APIKey:     os.Getenv("SANDARB_API_KEY"),
-		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		APIKey:     cfg.APIKey,
+		AgentID:    cfg.AgentID,
+		HTTPClient: &http.Client{Timeout: timeout, Transport: SharedTransport()},
+
+		payloadLimit: &payloadLimit{maxBytes: DefaultMaxPayloadBytes, strategy: TruncateHead},
+		noTelemetry:  cfg.DisableTelemetry,
 	}
 	for _, o := range opts {
 		o(c)
@@ -80,8 +179,14 @@ func (c *Client) headers(agentID, traceID string) map[string]string {
 		"Content-Type": "application/json",
 		"Accept":       "application/json",
 	}
-	if c.APIKey != "" {
-		h["Authorization"] = "Bearer " + c.APIKey
+	key := c.APIKey
+	if c.credentials != nil && agentID != "" {
+		if k := c.credentials(agentID); k != "" {
+			key = k
+		}
+	}
+	if key != "" {
+		h["Authorization"] = "Bearer " + key
 	}
 	if agentID != "" {
 		h["X-Sandarb-Agent-ID"] = agentID
@@ -89,85 +194,440 @@ func (c *Client) headers(agentID, traceID string) map[string]string {
 	if traceID != "" {
 		h["X-Sandarb-Trace-ID"] = traceID
 	}
+	if c.region != "" {
+		h[regionHeader] = c.region
+	}
+	c.setTelemetryHeaders(h)
 	return h
 }
 
-func (c *Client) do(req *http.Request) (*http.Response, error) {
+func (c *Client) httpClient() *http.Client {
 	if c.HTTPClient == nil {
 		c.HTTPClient = &http.Client{Timeout: 30 * time.Second}
 	}
-	resp, err := c.HTTPClient.Do(req)
+	return c.HTTPClient
+}
+
+// do issues req with hc, turning non-2xx responses other than accept into *SandarbError.
+func (c *Client) do(hc *http.Client, req *http.Request, accept int) (*http.Response, error) {
+	if c.signer != nil {
+		if err := c.signer.sign(req); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := hc.Do(req)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+	if c.signer != nil && c.signer.observe(resp.Header.Get("Date")) && resp.StatusCode == http.StatusUnauthorized {
+		// The local clock drifted from the server's; re-sign with the corrected timestamp once.
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			retry.Body = body
+		}
+		if err := c.signer.sign(retry); err == nil {
+			resp.Body.Close()
+			if resp, err = hc.Do(retry); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if (resp.StatusCode < 200 || resp.StatusCode >= 300) && resp.StatusCode != accept {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		return nil, &SandarbError{
 			Message:    fmt.Sprintf("API error: %s", resp.Status),
 			StatusCode: resp.StatusCode,
 			Body:       string(body),
+			Meta:       responseMetaFrom(resp.Header, ""),
 		}
 	}
 	return resp, nil
 }
 
+// apiRequest describes one logical API call; send may issue it against several endpoints.
+type apiRequest struct {
+	op          string  // SDK method name, used for metrics
+	feature     Feature // optional server capability the call depends on
+	method      string
+	path        string // path plus query string
+	body        []byte
+	contentType string // defaults to application/json
+	agentID     string
+	traceID     string
+	opts        []RequestOption
+	// acceptStatus is a non-2xx status returned as a response rather than an error, for
+	// endpoints that report data with it (an unhealthy health check).
+	acceptStatus int
+	// stream marks a response body that may take longer to read than HTTPClient.Timeout allows
+	// (a report export); the call is bounded by its context and WithRequestTimeout instead.
+	stream bool
+
+	servedRegion string       // set by send from the response's region header
+	meta         ResponseMeta // set by send from the successful response's headers
+}
+
+// send issues r against the first healthy endpoint, failing over to FallbackURLs on
+// transport errors and 5xx responses. It returns the response and the base URL that served it.
+func (c *Client) send(r *apiRequest) (resp *http.Response, servedBy string, err error) {
+	op := r.op
+	ro := newRequestOptions(r.opts)
+	if ro.traceID != "" {
+		r.traceID = ro.traceID
+	}
+	began := time.Now()
+	defer func() {
+		var se *SandarbError
+		if errors.As(err, &se) && se.Meta.ServedBy == "" {
+			se.Meta.ServedBy = servedBy
+		}
+		if ro.meta != nil {
+			if resp != nil {
+				*ro.meta = r.meta
+			} else if se != nil {
+				*ro.meta = se.Meta
+			}
+		}
+		c.incidents.trace(r.agentID, r.traceID)
+		if err != nil {
+			c.reportFailure(r, err, servedBy, time.Since(began))
+		}
+	}()
+	if c.dryRun != nil {
+		resp, servedBy, err = c.dryRun.serve(r)
+		if resp != nil {
+			r.meta = responseMetaFrom(resp.Header, servedBy)
+		}
+		return resp, servedBy, err
+	}
+	ctx, cancel := ro.ctx, context.CancelFunc(func() {})
+	if ro.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, ro.timeout)
+	}
+	if mctx, mcancel, ok := c.reserveMargin(ctx); ok {
+		outer := cancel
+		ctx, cancel = mctx, func() { mcancel(); outer() }
+	}
+	path := withQuery(r.path, ro.query)
+	pool := c.endpoints()
+	if len(pool.eps) == 0 && c.region != "" {
+		cancel()
+		return nil, "", &ResidencyError{Region: c.region}
+	}
+	eps, probe := pool.candidates()
+	for _, ep := range probe {
+		c.probe(pool, ep)
+	}
+	hc := c.httpClient()
+	if r.stream {
+		cp := *hc
+		cp.Timeout = 0
+		hc = &cp
+	}
+	var lastErr error
+	for i, ep := range eps {
+		if i > 0 && c.Metrics != nil {
+			c.Metrics.Retry(op)
+		}
+		var rdr io.Reader
+		if r.body != nil {
+			rdr = bytes.NewReader(r.body)
+		}
+		req, err := http.NewRequestWithContext(ctx, r.method, ep.base+path, rdr)
+		if err != nil {
+			cancel()
+			return nil, "", err
+		}
+		for k, v := range c.headers(r.agentID, r.traceID) {
+			req.Header.Set(k, v)
+		}
+		if r.contentType != "" {
+			req.Header.Set("Content-Type", r.contentType)
+		}
+		for k, vs := range ro.header {
+			req.Header[k] = vs
+		}
+		if err := c.setDeadline(ctx, req); err != nil {
+			cancel()
+			if lastErr != nil {
+				err = fmt.Errorf("%w (last endpoint error: %v)", err, lastErr)
+			}
+			return nil, "", err
+		}
+		start := time.Now()
+		resp, err := c.do(hc, req, r.acceptStatus)
+		if c.Metrics != nil {
+			status := statusOf(err)
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			c.Metrics.ObserveRequest(op, status, time.Since(start))
+		}
+		if err == nil {
+			pool.success(ep)
+			if err := c.checkResidency(resp, ep.base); err != nil {
+				resp.Body.Close()
+				cancel()
+				return nil, ep.base, err
+			}
+			r.servedRegion = resp.Header.Get(regionHeader)
+			r.meta = responseMetaFrom(resp.Header, ep.base)
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, ep.base, nil
+		}
+		if !isEndpointFailure(err) {
+			pool.success(ep)
+			cancel()
+			return nil, ep.base, err
+		}
+		if ctx.Err() != nil {
+			cancel()
+			return nil, "", err
+		}
+		pool.failure(ep)
+		lastErr = err
+		if !failoverSafe(r.method, ro.header) {
+			break
+		}
+	}
+	cancel()
+	if lastErr == nil {
+		lastErr = fmt.Errorf("sandarb: no base URL configured")
+	}
+	return nil, "", lastErr
+}
+
+// call sends r with in (if non-nil) as the JSON body and decodes the ApiResponse
+// envelope ({ success, data }) into out (if non-nil). It returns the base URL that served it.
+func (c *Client) call(r *apiRequest, in, out interface{}) (string, error) {
+	if r.feature != "" {
+		if err := c.requireFeature(r.feature, r.opts); err != nil {
+			return "", err
+		}
+	}
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return "", err
+		}
+		r.body = b
+	}
+	began := time.Now()
+	resp, servedBy, err := c.send(r)
+	if err != nil {
+		return servedBy, err
+	}
+	defer resp.Body.Close()
+	if err := decodeEnvelope(resp, r.op, out); err != nil {
+		c.reportFailure(r, err, servedBy, time.Since(began))
+		return servedBy, err
+	}
+	return servedBy, nil
+}
+
+// decodeEnvelope decodes an ApiResponse body ({ success, data, error }) into out.
+func decodeEnvelope(resp *http.Response, op string, out interface{}) error {
+	var envelope struct {
+		Success bool            `json:"success"`
+		Data    json.RawMessage `json:"data"`
+		Error   string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return err
+	}
+	if !envelope.Success {
+		msg := "invalid " + op + " response"
+		if envelope.Error != "" {
+			msg = envelope.Error
+		}
+		return &SandarbError{Message: msg, StatusCode: resp.StatusCode}
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
+
 // GetContext fetches context by name for the given agent.
 // Returns content + context_version_id (from context_versions).
-func (c *Client) GetContext(ctxName, agentID string) (*GetContextResult, error) {
-	traceID := uuid.New().String()
-	u := c.BaseURL + "/api/inject?name=" + url.QueryEscape(ctxName) + "&format=json"
-	req, err := http.NewRequest(http.MethodGet, u, nil)
+// Encrypted payloads are decrypted client-side with the configured KeyProvider.
+func (c *Client) GetContext(ctxName, agentID string, opts ...RequestOption) (*GetContextResult, error) {
+	return c.getContext(ctxName, agentID, traceIDOr(opts, newTraceID), opts)
+}
+
+func (c *Client) getContext(ctxName, agentID, traceID string, opts []RequestOption) (*GetContextResult, error) {
+	if r, ok := c.storedContext(ctxName, agentID, opts); ok {
+		if c.Metrics != nil {
+			c.Metrics.CacheHit("GetContext")
+		}
+		return r, nil
+	}
+	v, shared, err := c.coalesce("context\x00"+agentID+"\x00"+ctxName, opts, func() (interface{}, error) {
+		return c.fetchContext(ctxName, agentID, traceID, opts)
+	})
 	if err != nil {
 		return nil, err
 	}
-	for k, v := range c.headers(agentID, traceID) {
-		req.Header.Set(k, v)
+	res := v.(*GetContextResult)
+	if !shared {
+		c.storeContext(ctxName, agentID, res, opts)
 	}
-	resp, err := c.do(req)
+	// The fetched result may be read by other waiters, so every caller, the one that fetched it
+	// included, gets its own Content; raw is never modified and is shared.
+	cp := *res
+	cp.Content = cloneValue(res.Content).(map[string]interface{})
+	return &cp, nil
+}
+
+func (c *Client) fetchContext(ctxName, agentID, traceID string, opts []RequestOption) (*GetContextResult, error) {
+	path := "/api/inject?name=" + url.QueryEscape(ctxName) + "&format=json"
+	r := &apiRequest{op: "GetContext", method: http.MethodGet, path: path, agentID: agentID, traceID: traceID, opts: opts}
+	began := time.Now()
+	resp, servedBy, err := c.send(r)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	var content map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&content); err != nil {
+		c.reportFailure(r, err, servedBy, time.Since(began))
+		return nil, err
+	}
+	return c.contextFromResponse(ctxName, content, resp, servedBy)
+}
+
+// contextFromResponse builds a GetContextResult from decoded content and the /api/inject response headers.
+func (c *Client) contextFromResponse(ctxName string, content map[string]interface{}, resp *http.Response, servedBy string) (*GetContextResult, error) {
+	versionID, schemaVersion := contextHeaders(resp)
+	out, err := c.contextResult(ctxName, content, versionID, schemaVersion, servedBy)
+	if err != nil {
 		return nil, err
 	}
+	out.Region = resp.Header.Get(regionHeader)
+	out.meta = responseMetaFrom(resp.Header, servedBy)
+	return out, nil
+}
+
+// contextHeaders returns the context version ID and schema version reported by /api/inject.
+func contextHeaders(resp *http.Response) (versionID *string, schemaVersion int) {
+	if v := resp.Header.Get("X-Context-Version-ID"); v != "" {
+		versionID = &v
+	}
+	schemaVersion, _ = strconv.Atoi(resp.Header.Get("X-Context-Schema-Version"))
+	return versionID, schemaVersion
+}
+
+// contextResult builds a GetContextResult, decrypting envelope-encrypted content and applying
+// registered migrations.
+func (c *Client) contextResult(ctxName string, content map[string]interface{}, versionID *string, schemaVersion int, servedBy string) (*GetContextResult, error) {
 	if content == nil {
 		content = make(map[string]interface{})
 	}
-	out := &GetContextResult{Content: content}
-	if v := resp.Header.Get("X-Context-Version-ID"); v != "" {
-		out.ContextVersionID = &v
+	out := &GetContextResult{Content: content, ContextVersionID: versionID, SchemaVersion: schemaVersion, ServedBy: servedBy, raw: content}
+	if env, ok := encryptedEnvelope(content); ok {
+		plain, err := decryptEnvelope(c.KeyProvider, env)
+		if err != nil {
+			return nil, err
+		}
+		out.Content = plain
+		out.Decrypted = true
+	}
+	if err := c.migrate(ctxName, out); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
 
 // GetPrompt fetches compiled prompt by name with optional variable substitution.
-// agentID is required (or set SANDARB_AGENT_ID).
-func (c *Client) GetPrompt(promptName string, variables map[string]interface{}, agentID, traceID string) (*GetPromptResult, error) {
+// agentID is required (or set Client.AgentID / SANDARB_AGENT_ID). When the server publishes a
+// manifest for the prompt, variables are checked locally first and a *VariablesError is returned
+// for missing or mistyped values. WithTokenBudget adds token counts and a context window check.
+func (c *Client) GetPrompt(promptName string, variables map[string]interface{}, agentID, traceID string, opts ...RequestOption) (*GetPromptResult, error) {
 	if agentID == "" {
-		agentID = os.Getenv("SANDARB_AGENT_ID")
+		agentID = c.AgentID
 	}
 	if agentID == "" {
 		return nil, fmt.Errorf("agent_id is required for GetPrompt (or set SANDARB_AGENT_ID)")
 	}
 	if traceID == "" {
-		traceID = uuid.New().String()
-	}
-	u := c.BaseURL + "/api/prompts/pull?name=" + url.QueryEscape(promptName)
-	if len(variables) > 0 {
-		b, _ := json.Marshal(variables)
-		u += "&vars=" + url.QueryEscape(string(b))
+		traceID = newTraceID()
 	}
-	req, err := http.NewRequest(http.MethodGet, u, nil)
+	variables, err := c.scanVariables("GetPrompt", variables)
 	if err != nil {
 		return nil, err
 	}
-	for k, v := range c.headers(agentID, traceID) {
-		req.Header.Set(k, v)
+	if err := c.validateVariables(promptName, variables, opts); err != nil {
+		return nil, err
 	}
-	resp, err := c.do(req)
+	cacheKey, cacheable := promptCacheKey(agentID, promptName, 0, variables)
+	if r, ok := c.cachedPrompt("GetPrompt", cacheKey, cacheable, opts); ok {
+		return c.checkTokenBudget(promptName, r, opts)
+	}
+	var out *GetPromptResult
+	if cacheable {
+		v, shared, err := c.coalesce("prompt\x00"+cacheKey, opts, func() (interface{}, error) {
+			return c.fetchPrompt(promptName, variables, agentID, traceID, opts)
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = v.(*GetPromptResult)
+		if !shared {
+			c.storePrompt(cacheKey, out, opts)
+			if c.promptCache != nil {
+				c.promptCache.put(cacheKey, out)
+			}
+		}
+		// Other waiters read the fetched result, so each caller post-processes its own copy.
+		cp := *out
+		out = &cp
+	} else if out, err = c.fetchPrompt(promptName, variables, agentID, traceID, opts); err != nil {
+		return nil, err
+	}
+	return c.checkTokenBudget(promptName, out, opts)
+}
+
+// cachedPrompt looks key up in the in-memory prompt cache, then the CacheStore, promoting
+// store hits into memory.
+func (c *Client) cachedPrompt(method, key string, cacheable bool, opts []RequestOption) (*GetPromptResult, bool) {
+	if !cacheable {
+		return nil, false
+	}
+	var r *GetPromptResult
+	ok := false
+	if c.promptCache != nil {
+		r, ok = c.promptCache.get(key)
+	}
+	if !ok {
+		if r, ok = c.storedPrompt(key, opts); ok && c.promptCache != nil {
+			c.promptCache.put(key, r)
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	if c.Metrics != nil {
+		c.Metrics.CacheHit(method)
+	}
+	r.Cached = true
+	return r, true
+}
+
+// fetchPrompt pulls a compiled prompt from the server.
+func (c *Client) fetchPrompt(promptName string, variables map[string]interface{}, agentID, traceID string, opts []RequestOption) (*GetPromptResult, error) {
+	path := "/api/prompts/pull?name=" + url.QueryEscape(promptName)
+	if len(variables) > 0 {
+		b, _ := json.Marshal(variables)
+		path += "&vars=" + url.QueryEscape(string(b))
+	}
+	r := &apiRequest{op: "GetPrompt", method: http.MethodGet, path: path, agentID: agentID, traceID: traceID, opts: opts}
+	began := time.Now()
+	resp, servedBy, err := c.send(r)
 	if err != nil {
 		return nil, err
 	}
@@ -175,53 +635,135 @@ func (c *Client) GetPrompt(promptName string, variables map[string]interface{},
 	var envelope struct {
 		Success bool `json:"success"`
 		Data    struct {
-			Content       string  `json:"content"`
-			Version       int     `json:"version"`
-			Model         *string `json:"model"`
-			SystemPrompt  *string `json:"systemPrompt"`
+			Content      string  `json:"content"`
+			Version      int     `json:"version"`
+			Model        *string `json:"model"`
+			SystemPrompt *string `json:"systemPrompt"`
 		} `json:"data"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		c.reportFailure(r, err, servedBy, time.Since(began))
 		return nil, err
 	}
 	if !envelope.Success {
-		return nil, &SandarbError{Message: "invalid get_prompt response", StatusCode: resp.StatusCode}
+		err := &SandarbError{Message: "invalid get_prompt response", StatusCode: resp.StatusCode}
+		c.reportFailure(r, err, servedBy, time.Since(began))
+		return nil, err
 	}
-	out := &GetPromptResult{
+	return &GetPromptResult{
 		Content:      envelope.Data.Content,
 		Version:      envelope.Data.Version,
 		Model:        envelope.Data.Model,
 		SystemPrompt: envelope.Data.SystemPrompt,
+		ServedBy:     servedBy,
+		Region:       resp.Header.Get(regionHeader),
+		meta:         responseMetaFrom(resp.Header, servedBy),
+	}, nil
+}
+
+// PreviewPrompt compiles a prompt with sample variables for review tools and tests.
+// It does not count as a production pull and needs no agent ID. version 0 means the latest version.
+func (c *Client) PreviewPrompt(promptName string, variables map[string]interface{}, version int, opts ...RequestOption) (*GetPromptResult, error) {
+	variables, err := c.scanVariables("PreviewPrompt", variables)
+	if err != nil {
+		return nil, err
+	}
+	path := "/api/prompts/preview?name=" + url.QueryEscape(promptName)
+	if version > 0 {
+		path += "&version=" + strconv.Itoa(version)
+	}
+	if len(variables) > 0 {
+		b, err := json.Marshal(variables)
+		if err != nil {
+			return nil, err
+		}
+		path += "&vars=" + url.QueryEscape(string(b))
+	}
+	// Previews carry no agent, so they never share entries with GetPrompt.
+	cacheKey, cacheable := promptCacheKey("", promptName, version, variables)
+	if r, ok := c.cachedPrompt("PreviewPrompt", cacheKey, cacheable, opts); ok {
+		return r, nil
+	}
+	var data struct {
+		Content      string  `json:"content"`
+		Version      int     `json:"version"`
+		Model        *string `json:"model"`
+		SystemPrompt *string `json:"systemPrompt"`
+	}
+	r := &apiRequest{op: "PreviewPrompt", feature: FeaturePromptPreview, method: http.MethodGet, path: path, opts: opts}
+	servedBy, err := c.call(r, nil, &data)
+	if err != nil {
+		return nil, err
+	}
+	out := &GetPromptResult{
+		Content:      data.Content,
+		Version:      data.Version,
+		Model:        data.Model,
+		SystemPrompt: data.SystemPrompt,
+		ServedBy:     servedBy,
+		Region:       r.servedRegion,
+		meta:         r.meta,
+	}
+	if cacheable {
+		c.storePrompt(cacheKey, out, opts)
+		if c.promptCache != nil {
+			c.promptCache.put(cacheKey, out)
+		}
 	}
 	return out, nil
 }
 
 // LogActivity writes an activity record to sandarb_access_logs (metadata = { inputs, outputs }).
-func (c *Client) LogActivity(agentID, traceID string, inputs, outputs map[string]interface{}) error {
-	if inputs == nil {
-		inputs = make(map[string]interface{})
+func (c *Client) LogActivity(agentID, traceID string, inputs, outputs map[string]interface{}, opts ...RequestOption) error {
+	return c.logActivity(&ActivityRecord{AgentID: agentID, TraceID: traceID, Inputs: inputs, Outputs: outputs}, opts)
+}
+
+// logActivity is the write path shared by LogActivity and higher-level helpers such as Session.
+// Records dropped by sampling are not sent, oversized ones are truncated, and records that fail with an endpoint failure are
+// spooled when a Spool is configured. A configured ActivitySink receives records instead of, or as well as, the API.
+func (c *Client) logActivity(rec *ActivityRecord, opts []RequestOption) error {
+	if rec.Inputs == nil {
+		rec.Inputs = make(map[string]interface{})
+	}
+	if rec.Outputs == nil {
+		rec.Outputs = make(map[string]interface{})
+	}
+	if ro := newRequestOptions(opts); len(ro.attachments) > 0 || ro.subjectID != "" {
+		if rec.Metadata == nil {
+			rec.Metadata = make(map[string]interface{})
+		}
+		if len(ro.attachments) > 0 {
+			rec.Metadata["attachments"] = ro.attachments
+		}
+		if ro.subjectID != "" {
+			rec.Metadata["subject_id"] = ro.subjectID
+		}
 	}
-	if outputs == nil {
-		outputs = make(map[string]interface{})
+	if c.secrets != nil {
+		p, err := c.secrets.scan("LogActivity", map[string]map[string]interface{}{"inputs": rec.Inputs, "outputs": rec.Outputs, "metadata": rec.Metadata})
+		if err != nil {
+			return err
+		}
+		rec.Inputs, rec.Outputs, rec.Metadata = p["inputs"], p["outputs"], p["metadata"]
 	}
-	body := map[string]interface{}{
-		"agent_id": agentID,
-		"trace_id": traceID,
-		"inputs":   inputs,
-		"outputs":  outputs,
+	if c.sampler != nil && !c.sampler.sample(rec) {
+		return nil
 	}
-	b, err := json.Marshal(body)
-	if err != nil {
-		return err
+	if c.payloadLimit != nil {
+		if err := c.payloadLimit.apply(rec); err != nil {
+			return err
+		}
 	}
-	req, err := http.NewRequest(http.MethodPost, c.BaseURL+"/api/audit/activity", bytes.NewReader(b))
+	return c.deliverActivity(rec, opts)
+}
+
+// postActivity sends one record to the audit API.
+func (c *Client) postActivity(rec *ActivityRecord, opts []RequestOption) error {
+	b, err := json.Marshal(rec)
 	if err != nil {
 		return err
 	}
-	for k, v := range c.headers(agentID, traceID) {
-		req.Header.Set(k, v)
-	}
-	resp, err := c.do(req)
+	resp, _, err := c.send(&apiRequest{op: "LogActivity", method: http.MethodPost, path: "/api/audit/activity", body: b, agentID: rec.AgentID, traceID: rec.TraceID, opts: opts})
 	if err != nil {
 		return err
 	}
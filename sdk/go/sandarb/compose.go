@@ -0,0 +1,263 @@
+package sandarb
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MergeStrategy controls how ComposeContexts combines contexts. Contexts are merged in the
+// order given, so later (more specific) contexts take precedence over earlier ones.
+type MergeStrategy int
+
+const (
+	// MergeDeep recursively merges objects; on conflicting values the later context wins.
+	MergeDeep MergeStrategy = iota
+	// MergeConcat recursively merges objects, concatenating arrays and strings in order;
+	// other conflicting values are resolved as in MergeDeep.
+	MergeConcat
+)
+
+const composeParallelism = 8
+
+// MergeConflict records a key set to different values by more than one context.
+type MergeConflict struct {
+	// Path is the dotted key path, e.g. "limits.max_refund".
+	Path string
+	// Contexts lists the contexts that set the key, in merge order; the last one won.
+	Contexts []string
+}
+
+// ComposedPart is one fetched input of a composition.
+type ComposedPart struct {
+	Name             string
+	Content          map[string]interface{}
+	ContextVersionID *string
+}
+
+// ComposedContext is the merged result of ComposeContexts.
+type ComposedContext struct {
+	Content   map[string]interface{}
+	Parts     []ComposedPart
+	Conflicts []MergeConflict
+}
+
+// VersionIDs returns context name -> context_version_id for every part, for inclusion in LogActivity.
+func (cc *ComposedContext) VersionIDs() map[string]string {
+	out := make(map[string]string, len(cc.Parts))
+	for _, p := range cc.Parts {
+		if p.ContextVersionID != nil {
+			out[p.Name] = *p.ContextVersionID
+		}
+	}
+	return out
+}
+
+// ComposeContexts fetches the named contexts for agentID (in one batch request when the server
+// supports it, otherwise concurrently) and merges them in order with strategy (e.g. org policy,
+// then team rules, then task context), reporting key conflicts.
+func (c *Client) ComposeContexts(agentID string, names []string, strategy MergeStrategy, opts ...RequestOption) (*ComposedContext, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("sandarb: at least one context name is required for ComposeContexts")
+	}
+	results, err := c.getContexts(agentID, names, opts)
+	if err != nil {
+		return nil, wrapError(err, "compose")
+	}
+
+	out := &ComposedContext{Content: make(map[string]interface{})}
+	m := &merge{strategy: strategy, owners: make(map[string][]string), conflicts: make(map[string]bool)}
+	for i, r := range results {
+		out.Parts = append(out.Parts, ComposedPart{Name: names[i], Content: r.Content, ContextVersionID: r.ContextVersionID})
+		m.into(out.Content, r.Content, "", names[i])
+	}
+	paths := make([]string, 0, len(m.conflicts))
+	for p := range m.conflicts {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		out.Conflicts = append(out.Conflicts, MergeConflict{Path: p, Contexts: m.owners[p]})
+	}
+	return out, nil
+}
+
+// merge tracks the contexts that set each path while contexts are merged in order.
+type merge struct {
+	strategy MergeStrategy
+	// owners lists, per path, every context that set it, in merge order.
+	owners map[string][]string
+	// conflicts holds the paths that were set to different values.
+	conflicts map[string]bool
+}
+
+// into merges src, from context from, into dst.
+func (m *merge) into(dst, src map[string]interface{}, prefix, from string) {
+	keys := make([]string, 0, len(src))
+	for k := range src {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := src[k]
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		existing, ok := dst[k]
+		if !ok {
+			dst[k] = cloneValue(v)
+			m.recordOwner(path, v, from)
+			continue
+		}
+		em, eIsMap := existing.(map[string]interface{})
+		vm, vIsMap := v.(map[string]interface{})
+		if eIsMap && vIsMap {
+			m.into(em, vm, path, from)
+			continue
+		}
+		if m.strategy == MergeConcat {
+			if es, ok := existing.([]interface{}); ok {
+				if vs, ok := v.([]interface{}); ok {
+					dst[k] = append(es, cloneValue(vs).([]interface{})...)
+					continue
+				}
+			}
+			if es, ok := existing.(string); ok {
+				if vs, ok := v.(string); ok {
+					dst[k] = strings.TrimRight(es, "\n") + "\n\n" + vs
+					continue
+				}
+			}
+		}
+		// Every context that sets the key is recorded, including one repeating the current value
+		// after a conflict, so MergeConflict names all of the sources.
+		m.owners[path] = append(m.owners[path], from)
+		if !reflect.DeepEqual(existing, v) {
+			m.conflicts[path] = true
+		}
+		dst[k] = cloneValue(v)
+	}
+}
+
+// recordOwner marks from as the setter of path and, for objects, of every nested path.
+func (m *merge) recordOwner(path string, v interface{}, from string) {
+	m.owners[path] = []string{from}
+	if obj, ok := v.(map[string]interface{}); ok {
+		for k, vv := range obj {
+			m.recordOwner(path+"."+k, vv, from)
+		}
+	}
+}
+
+// wrapError prefixes err with "sandarb: " and msg, dropping err's own "sandarb: " prefix so
+// nested errors read "sandarb: compose: context "x": ..." rather than repeating it.
+func wrapError(err error, format string, args ...interface{}) error {
+	return &wrappedError{msg: fmt.Sprintf(format, args...), err: err}
+}
+
+type wrappedError struct {
+	msg string
+	err error
+}
+
+func (e *wrappedError) Error() string {
+	return "sandarb: " + e.msg + ": " + strings.TrimPrefix(e.err.Error(), "sandarb: ")
+}
+
+func (e *wrappedError) Unwrap() error { return e.err }
+
+// cloneValue deep-copies decoded JSON so merged output never aliases a part's content.
+func cloneValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, vv := range t {
+			m[k] = cloneValue(vv)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(t))
+		for i, vv := range t {
+			s[i] = cloneValue(vv)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// getContexts fetches names for agentID, preferring the batch endpoint and falling back to
+// bounded concurrent single fetches on servers without FeatureBatchContexts.
+func (c *Client) getContexts(agentID string, names []string, opts []RequestOption) ([]*GetContextResult, error) {
+	traceID := traceIDOr(opts, newTraceID)
+	if c.supports(FeatureBatchContexts, opts) {
+		return c.getContextsBatch(agentID, traceID, names, opts)
+	}
+	results := make([]*GetContextResult, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, composeParallelism)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = c.getContext(name, agentID, traceID, opts)
+		}(i, name)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, wrapError(err, "context %q", names[i])
+		}
+	}
+	return results, nil
+}
+
+func (c *Client) getContextsBatch(agentID, traceID string, names []string, opts []RequestOption) ([]*GetContextResult, error) {
+	var items []struct {
+		Name             string                 `json:"name"`
+		Content          map[string]interface{} `json:"content"`
+		ContextVersionID *string                `json:"context_version_id"`
+		SchemaVersion    int                    `json:"schema_version"`
+		Error            string                 `json:"error"`
+	}
+	r := &apiRequest{
+		op:      "GetContexts",
+		method:  http.MethodPost,
+		path:    "/api/inject/batch",
+		agentID: agentID,
+		traceID: traceID,
+		opts:    opts,
+	}
+	servedBy, err := c.call(r, map[string]interface{}{"names": names}, &items)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]int, len(items))
+	for i, it := range items {
+		byName[it.Name] = i
+	}
+	results := make([]*GetContextResult, len(names))
+	for i, name := range names {
+		j, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("sandarb: context %q missing from batch response", name)
+		}
+		it := items[j]
+		if it.Error != "" {
+			return nil, fmt.Errorf("sandarb: context %q: %s", name, it.Error)
+		}
+		if results[i], err = c.contextResult(name, it.Content, it.ContextVersionID, it.SchemaVersion, servedBy); err != nil {
+			return nil, wrapError(err, "context %q", name)
+		}
+		results[i].Region = r.servedRegion
+		results[i].meta = r.meta
+	}
+	return results, nil
+}
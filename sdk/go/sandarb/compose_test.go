@@ -0,0 +1,44 @@
+package sandarb
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMergeRecordsEveryConflictSource(t *testing.T) {
+	m := &merge{strategy: MergeDeep, owners: map[string][]string{}, conflicts: map[string]bool{}}
+	dst := map[string]interface{}{}
+	m.into(dst, map[string]interface{}{"limits": map[string]interface{}{"max": 100.0, "min": 1.0}}, "", "org")
+	m.into(dst, map[string]interface{}{"limits": map[string]interface{}{"max": 50.0}}, "", "team")
+	m.into(dst, map[string]interface{}{"limits": map[string]interface{}{"max": 50.0, "min": 1.0}}, "", "task")
+
+	if dst["limits"].(map[string]interface{})["max"] != 50.0 {
+		t.Errorf("merged = %v", dst)
+	}
+	if want := []string{"org", "team", "task"}; !reflect.DeepEqual(m.owners["limits.max"], want) {
+		t.Errorf("limits.max owners = %v, want %v", m.owners["limits.max"], want)
+	}
+	if !m.conflicts["limits.max"] || m.conflicts["limits.min"] {
+		t.Errorf("conflicts = %v, want only limits.max", m.conflicts)
+	}
+}
+
+func TestComposeContextsErrorPrefix(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL))
+	_, err := c.ComposeContexts("agent", []string{"org"}, MergeDeep)
+	var se *SandarbError
+	if !errors.As(err, &se) {
+		t.Fatalf("error = %v, want a wrapped *SandarbError", err)
+	}
+	if got, want := err.Error(), `sandarb: compose: context "org": `; len(got) < len(want) || got[:len(want)] != want {
+		t.Errorf("error = %q, want prefix %q", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+package sandarb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// deadlineHeader tells the server how many milliseconds the client will wait for the response.
+// A relative budget is sent rather than a timestamp so client/server clock skew doesn't matter.
+const deadlineHeader = "X-Sandarb-Deadline"
+
+// WithDeadlineMargin reserves d of each call's context deadline for local processing: the request
+// itself (including reading the response body) is bounded by the deadline less d, the server is
+// told it has that much time, and calls whose remaining time is already below d fail with
+// context.DeadlineExceeded without being sent.
+func WithDeadlineMargin(d time.Duration) ClientOption {
+	return func(c *Client) { c.deadlineMargin = d }
+}
+
+// reserveMargin returns ctx with its deadline brought forward by the configured margin. It
+// reports false, leaving ctx as is, when there is no margin or no deadline.
+func (c *Client) reserveMargin(ctx context.Context) (context.Context, context.CancelFunc, bool) {
+	dl, ok := ctx.Deadline()
+	if !ok || c.deadlineMargin <= 0 {
+		return ctx, nil, false
+	}
+	ctx, cancel := context.WithDeadline(ctx, dl.Add(-c.deadlineMargin))
+	return ctx, cancel, true
+}
+
+// setDeadline propagates ctx's deadline (already less the configured margin) to req. It is
+// evaluated per attempt so failover retries advertise only the time that is left.
+func (c *Client) setDeadline(ctx context.Context, req *http.Request) error {
+	dl, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	budget := time.Until(dl)
+	if budget <= 0 {
+		return fmt.Errorf("sandarb: deadline budget exhausted (margin %s): %w", c.deadlineMargin, context.DeadlineExceeded)
+	}
+	ms := budget.Milliseconds()
+	if ms == 0 {
+		ms = 1
+	}
+	req.Header.Set(deadlineHeader, strconv.FormatInt(ms, 10))
+	return nil
+}
@@ -0,0 +1,83 @@
+package sandarb
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetContextIfChanged refetches a context previously returned by GetContext (or by an earlier
+// GetContextIfChanged), sending prev's context_version_id as a conditional request. The server
+// can answer 304 Not Modified (prev's content is reused; NotModified is set) or 226 IM Used with
+// a JSON Patch against prev (applied locally; Delta is set). If the patch can't be applied, or
+// prev has no version ID, the context is fetched in full.
+//
+// prev.Content must not have been modified by the caller, since patches apply to it.
+func (c *Client) GetContextIfChanged(ctxName, agentID string, prev *GetContextResult, opts ...RequestOption) (*GetContextResult, error) {
+	traceID := traceIDOr(opts, newTraceID)
+	if prev == nil || prev.ContextVersionID == nil || prev.raw == nil {
+		return c.getContext(ctxName, agentID, traceID, opts)
+	}
+	etag := strconv.Quote(*prev.ContextVersionID)
+	condOpts := append(append([]RequestOption(nil), opts...), WithHeader("If-None-Match", etag), WithHeader("A-IM", "json-patch"))
+	path := "/api/inject?name=" + url.QueryEscape(ctxName) + "&format=json"
+	r := &apiRequest{op: "GetContext", method: http.MethodGet, path: path, agentID: agentID, traceID: traceID, opts: condOpts}
+	began := time.Now()
+	resp, servedBy, err := c.send(r)
+	var se *SandarbError
+	if errors.As(err, &se) && se.StatusCode == http.StatusNotModified {
+		out := *prev
+		out.ServedBy = servedBy
+		out.meta = se.Meta
+		out.NotModified, out.Delta = true, false
+		return &out, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusIMUsed {
+		var content map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&content); err != nil {
+			c.reportFailure(r, err, servedBy, time.Since(began))
+			return nil, err
+		}
+		out, err := c.contextFromResponse(ctxName, content, resp, servedBy)
+		if err != nil {
+			return nil, err
+		}
+		c.storeContext(ctxName, agentID, out, opts)
+		return out, nil
+	}
+	var ops []patchOp
+	if !strings.Contains(resp.Header.Get("IM"), "json-patch") || resp.Header.Get("Delta-Base") != etag || json.NewDecoder(resp.Body).Decode(&ops) != nil {
+		return c.refetchContext(ctxName, agentID, traceID, opts)
+	}
+	patched, err := applyJSONPatch(cloneValue(prev.raw), ops)
+	content, ok := patched.(map[string]interface{})
+	if err != nil || !ok {
+		return c.refetchContext(ctxName, agentID, traceID, opts)
+	}
+	out, err := c.contextFromResponse(ctxName, content, resp, servedBy)
+	if err != nil {
+		return nil, err
+	}
+	out.Delta = true
+	c.storeContext(ctxName, agentID, out, opts)
+	return out, nil
+}
+
+// refetchContext fetches a context in full, bypassing the CacheStore (which may hold a version
+// the server has just reported as superseded) and refreshing it.
+func (c *Client) refetchContext(ctxName, agentID, traceID string, opts []RequestOption) (*GetContextResult, error) {
+	out, err := c.fetchContext(ctxName, agentID, traceID, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.storeContext(ctxName, agentID, out, opts)
+	return out, nil
+}
@@ -0,0 +1,70 @@
+package sandarb
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Subscription is a context or prompt an agent pulls, with the version it last received and
+// the latest published version.
+type Subscription struct {
+	Resource ResourceRef `json:"resource"`
+	// InUseVersionID is the version the agent last pulled; Resource.VersionID is unset.
+	InUseVersionID    string     `json:"in_use_version_id"`
+	LatestVersionID   string     `json:"latest_version_id"`
+	LastPulledAt      *time.Time `json:"last_pulled_at,omitempty"`
+	LatestPublishedAt *time.Time `json:"latest_published_at,omitempty"`
+}
+
+// Stale reports whether a newer version has been published than the one the agent uses.
+func (s *Subscription) Stale() bool {
+	return s.LatestVersionID != "" && s.InUseVersionID != s.LatestVersionID
+}
+
+// DriftSummary compares the versions an agent uses against the latest published ones.
+type DriftSummary struct {
+	AgentID     string
+	GeneratedAt time.Time
+	// Current and Stale partition the agent's subscriptions.
+	Current []Subscription
+	Stale   []Subscription
+	// MaxStaleness is the longest time any stale subscription's latest version has been
+	// published (0 when none are stale or publish times are unknown).
+	MaxStaleness time.Duration
+}
+
+// Drifted reports whether any subscription is stale.
+func (d *DriftSummary) Drifted() bool { return len(d.Stale) > 0 }
+
+// DriftReport compares the context and prompt versions agentID last pulled (from the audit
+// trail) against the latest published versions. Run it per agent to find stale agents across a fleet.
+func (c *Client) DriftReport(agentID string, opts ...RequestOption) (*DriftSummary, error) {
+	if agentID == "" {
+		agentID = c.AgentID
+	}
+	if agentID == "" {
+		return nil, fmt.Errorf("sandarb: agent_id is required for DriftReport (or set SANDARB_AGENT_ID)")
+	}
+	var subs []Subscription
+	path := "/api/agents/" + url.PathEscape(agentID) + "/subscriptions"
+	if _, err := c.call(&apiRequest{op: "DriftReport", feature: FeatureSubscriptions, method: http.MethodGet, path: path, agentID: agentID, opts: opts}, nil, &subs); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := &DriftSummary{AgentID: agentID, GeneratedAt: now}
+	for _, s := range subs {
+		if !s.Stale() {
+			out.Current = append(out.Current, s)
+			continue
+		}
+		out.Stale = append(out.Stale, s)
+		if s.LatestPublishedAt != nil {
+			if age := now.Sub(*s.LatestPublishedAt); age > out.MaxStaleness {
+				out.MaxStaleness = age
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,221 @@
+package sandarb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dryRunServedBy is reported as ServedBy for responses produced by a dry run.
+const dryRunServedBy = "dry-run"
+
+// dryRunReadPaths are POST endpoints that only read, so a dry run doesn't record them as writes.
+var dryRunReadPaths = map[string]bool{
+	"/api/inject/batch":      true,
+	"/api/permissions/check": true,
+}
+
+// WithDryRun runs the client without a server: writes (LogActivity, approvals, registrations,
+// ...) are captured by the client's DryRun recorder instead of being sent, and reads are served
+// from fixtures (see Client.DryRun). Reads without a fixture fail with a 404 *SandarbError.
+// All optional features are treated as supported. Activity sinks and the spool are not written.
+func WithDryRun() ClientOption {
+	return func(c *Client) { c.dryRun = newDryRun() }
+}
+
+// RecordedWrite is a write captured by a dry run.
+type RecordedWrite struct {
+	Op      string // SDK method, e.g. "LogActivity"
+	Method  string
+	Path    string
+	AgentID string
+	TraceID string
+	Body    []byte
+	Time    time.Time
+}
+
+// DryRun holds fixtures and captured writes for a client created with WithDryRun.
+type DryRun struct {
+	mu        sync.Mutex
+	contexts  map[string]map[string]interface{}
+	prompts   map[string]string
+	responses map[string]interface{} // "METHOD /path" -> envelope data
+	writes    []RecordedWrite
+}
+
+func newDryRun() *DryRun {
+	return &DryRun{contexts: make(map[string]map[string]interface{}), prompts: make(map[string]string), responses: make(map[string]interface{})}
+}
+
+// DryRun returns the dry-run recorder, or nil if the client wasn't created with WithDryRun.
+func (c *Client) DryRun() *DryRun { return c.dryRun }
+
+// SetContext serves content for GetContext(name) and everything built on it.
+func (d *DryRun) SetContext(name string, content map[string]interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.contexts[name] = content
+}
+
+// SetPrompt serves content for GetPrompt(name). Variables are not substituted.
+func (d *DryRun) SetPrompt(name, content string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.prompts[name] = content
+}
+
+// SetResponse serves data as the ApiResponse payload for method and path (without query string),
+// e.g. SetResponse("POST", "/api/moderation", ModerationResult{...}). It takes precedence over
+// other fixtures, and a matching write is still recorded.
+func (d *DryRun) SetResponse(method, path string, data interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.responses[method+" "+path] = data
+}
+
+// LoadFixtures loads contexts from dir/contexts/<name>.json and prompts from dir/prompts/<name>.txt.
+// Missing subdirectories are ignored.
+func (d *DryRun) LoadFixtures(dir string) error {
+	ctxFiles, _ := filepath.Glob(filepath.Join(dir, "contexts", "*.json"))
+	for _, f := range ctxFiles {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		var content map[string]interface{}
+		if err := json.Unmarshal(b, &content); err != nil {
+			return fmt.Errorf("sandarb: fixture %s: %w", f, err)
+		}
+		d.SetContext(strings.TrimSuffix(filepath.Base(f), ".json"), content)
+	}
+	promptFiles, _ := filepath.Glob(filepath.Join(dir, "prompts", "*.txt"))
+	for _, f := range promptFiles {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		d.SetPrompt(strings.TrimSuffix(filepath.Base(f), ".txt"), string(b))
+	}
+	return nil
+}
+
+// Writes returns the writes captured so far, oldest first.
+func (d *DryRun) Writes() []RecordedWrite {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]RecordedWrite(nil), d.writes...)
+}
+
+// Activities returns the activity records captured from LogActivity and ImportActivities.
+func (d *DryRun) Activities() []ActivityRecord {
+	var out []ActivityRecord
+	for _, w := range d.Writes() {
+		switch w.Op {
+		case "LogActivity":
+			var rec ActivityRecord
+			if json.Unmarshal(w.Body, &rec) == nil {
+				out = append(out, rec)
+			}
+		case "ImportActivities":
+			sc := bufio.NewScanner(bytes.NewReader(w.Body))
+			sc.Buffer(nil, len(w.Body)+1)
+			for sc.Scan() {
+				var rec ActivityRecord
+				if json.Unmarshal(sc.Bytes(), &rec) == nil {
+					out = append(out, rec)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Reset discards captured writes. Fixtures are kept.
+func (d *DryRun) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.writes = nil
+}
+
+// serve answers r locally in place of send.
+func (d *DryRun) serve(r *apiRequest) (*http.Response, string, error) {
+	path, rawQuery, _ := strings.Cut(r.path, "?")
+	q, _ := url.ParseQuery(rawQuery)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if r.method != http.MethodGet && r.method != http.MethodHead && !dryRunReadPaths[path] {
+		d.writes = append(d.writes, RecordedWrite{
+			Op: r.op, Method: r.method, Path: r.path, AgentID: r.agentID, TraceID: r.traceID,
+			Body: append([]byte(nil), r.body...), Time: time.Now(),
+		})
+	}
+	if data, ok := d.responses[r.method+" "+path]; ok {
+		return dryRunResponse(map[string]interface{}{"success": true, "data": data})
+	}
+	switch {
+	case path == "/api/inject" && r.method == http.MethodGet:
+		content, ok := d.contexts[q.Get("name")]
+		if !ok {
+			return nil, dryRunServedBy, dryRunNotFound("context", q.Get("name"))
+		}
+		return dryRunResponse(content)
+	case path == "/api/inject/batch":
+		var req struct {
+			Names []string `json:"names"`
+		}
+		_ = json.Unmarshal(r.body, &req)
+		items := make([]map[string]interface{}, 0, len(req.Names))
+		for _, n := range req.Names {
+			if content, ok := d.contexts[n]; ok {
+				items = append(items, map[string]interface{}{"name": n, "content": content})
+			} else {
+				items = append(items, map[string]interface{}{"name": n, "error": "dry run: no fixture for context " + n})
+			}
+		}
+		return dryRunResponse(map[string]interface{}{"success": true, "data": items})
+	case path == "/api/prompts/pull" || path == "/api/prompts/preview":
+		content, ok := d.prompts[q.Get("name")]
+		if !ok {
+			return nil, dryRunServedBy, dryRunNotFound("prompt", q.Get("name"))
+		}
+		return dryRunResponse(map[string]interface{}{"success": true, "data": map[string]interface{}{"content": content, "version": 1}})
+	case path == "/api/audit/activity/bulk":
+		accepted := bytes.Count(bytes.TrimSpace(r.body), []byte("\n"))
+		if len(bytes.TrimSpace(r.body)) > 0 {
+			accepted++
+		}
+		return dryRunResponse(map[string]interface{}{"success": true, "data": map[string]interface{}{"accepted": accepted}})
+	case path == "/api/health":
+		return dryRunResponse(map[string]interface{}{"status": "healthy"})
+	case r.method == http.MethodGet || r.method == http.MethodHead:
+		return nil, dryRunServedBy, dryRunNotFound("fixture", r.method+" "+path)
+	}
+	return dryRunResponse(map[string]interface{}{"success": true})
+}
+
+func dryRunResponse(body interface{}) (*http.Response, string, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, dryRunServedBy, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+	}, dryRunServedBy, nil
+}
+
+func dryRunNotFound(kind, name string) error {
+	return &SandarbError{Message: fmt.Sprintf("dry run: no %s fixture for %q", kind, name), StatusCode: http.StatusNotFound}
+}
@@ -0,0 +1,139 @@
+package sandarb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// envelopeKey is the content key under which the server places an encrypted context payload.
+const envelopeKey = "sandarb_envelope"
+
+// envelopeAlg is the only envelope algorithm supported; data and key-encryption keys are 32 bytes.
+const envelopeAlg = "AES-256-GCM"
+
+// ErrNoKeyProvider is returned when an encrypted context is received but no KeyProvider is configured.
+var ErrNoKeyProvider = errors.New("sandarb: encrypted context received but no key provider configured")
+
+// KeyProvider unwraps the per-context data key of an encrypted payload.
+// Implementations typically call a KMS; LocalKeyProvider covers locally held keys.
+type KeyProvider interface {
+	UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// EncryptedEnvelope is the envelope-encrypted form of context content (AES-256-GCM).
+// The data key is wrapped by the key identified by KeyID; binary fields are base64 encoded.
+type EncryptedEnvelope struct {
+	Alg        string `json:"alg"`
+	KeyID      string `json:"key_id"`
+	WrappedKey string `json:"wrapped_key"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// WithKeyProvider sets the KeyProvider used to decrypt encrypted context payloads.
+func WithKeyProvider(kp KeyProvider) ClientOption {
+	return func(c *Client) { c.KeyProvider = kp }
+}
+
+// LocalKeyProvider unwraps data keys with key-encryption keys held in memory (AES-256-GCM, nonce prefixed).
+type LocalKeyProvider struct {
+	keys map[string][]byte
+}
+
+// NewLocalKeyProvider creates a LocalKeyProvider from key ID -> key-encryption key (32 bytes;
+// keys of any other length fail when used).
+func NewLocalKeyProvider(keys map[string][]byte) *LocalKeyProvider {
+	m := make(map[string][]byte, len(keys))
+	for id, k := range keys {
+		m[id] = append([]byte(nil), k...)
+	}
+	return &LocalKeyProvider{keys: m}
+}
+
+// UnwrapKey decrypts wrappedKey (nonce || ciphertext) with the key-encryption key for keyID.
+func (p *LocalKeyProvider) UnwrapKey(keyID string, wrappedKey []byte) ([]byte, error) {
+	kek, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("sandarb: unknown key id %q", keyID)
+	}
+	return openGCM(kek, wrappedKey)
+}
+
+// encryptedEnvelope returns the envelope embedded in content, if any.
+func encryptedEnvelope(content map[string]interface{}) (*EncryptedEnvelope, bool) {
+	raw, ok := content[envelopeKey]
+	if !ok {
+		return nil, false
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, false
+	}
+	var env EncryptedEnvelope
+	if err := json.Unmarshal(b, &env); err != nil || env.Ciphertext == "" {
+		return nil, false
+	}
+	return &env, true
+}
+
+// decryptEnvelope unwraps the data key via kp and returns the decrypted JSON content.
+func decryptEnvelope(kp KeyProvider, env *EncryptedEnvelope) (map[string]interface{}, error) {
+	if kp == nil {
+		return nil, ErrNoKeyProvider
+	}
+	if env.Alg != "" && env.Alg != envelopeAlg {
+		return nil, fmt.Errorf("sandarb: unsupported envelope algorithm %q", env.Alg)
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(env.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("sandarb: invalid wrapped_key: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(env.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("sandarb: invalid nonce: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(env.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("sandarb: invalid ciphertext: %w", err)
+	}
+	dataKey, err := kp.UnwrapKey(env.KeyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("sandarb: unwrap key %q: %w", env.KeyID, err)
+	}
+	plaintext, err := openGCM(dataKey, append(nonce, ciphertext...))
+	if err != nil {
+		return nil, fmt.Errorf("sandarb: decrypt context: %w", err)
+	}
+	var content map[string]interface{}
+	if err := json.Unmarshal(plaintext, &content); err != nil {
+		return nil, fmt.Errorf("sandarb: decode decrypted context: %w", err)
+	}
+	if content == nil {
+		content = make(map[string]interface{})
+	}
+	return content, nil
+}
+
+// openGCM decrypts data laid out as nonce || ciphertext with AES-256-GCM. Shorter keys are
+// rejected rather than silently using AES-128 or AES-192.
+func openGCM(key, data []byte) ([]byte, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("sandarb: %s requires a 32-byte key, got %d bytes", envelopeAlg, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("sandarb: ciphertext too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
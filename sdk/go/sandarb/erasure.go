@@ -0,0 +1,66 @@
+package sandarb
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErasureState is the progress of a data-subject erasure request.
+type ErasureState string
+
+const (
+	ErasurePending    ErasureState = "pending"
+	ErasureInProgress ErasureState = "in_progress"
+	ErasureCompleted  ErasureState = "completed"
+	ErasureFailed     ErasureState = "failed"
+)
+
+// ErasureRequest tracks deletion of a data subject's content from stored activity.
+type ErasureRequest struct {
+	ID          string       `json:"id"`
+	SubjectID   string       `json:"subject_id"`
+	State       ErasureState `json:"state"`
+	RequestedAt *time.Time   `json:"requested_at,omitempty"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	// RecordsErased is the number of activity records scrubbed so far.
+	RecordsErased int    `json:"records_erased"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Done reports whether the request has reached a terminal state.
+func (e *ErasureRequest) Done() bool {
+	return e.State == ErasureCompleted || e.State == ErasureFailed
+}
+
+// WithSubjectID tags the activity being logged with the data subject it concerns (metadata
+// "subject_id"), so RequestErasure can find it. It only affects LogActivity and Session.LogTurn.
+func WithSubjectID(subjectID string) RequestOption {
+	return func(o *requestOptions) { o.subjectID = subjectID }
+}
+
+// RequestErasure asks the server to erase the inputs and outputs of all activity tagged with
+// subjectID. Erasure runs asynchronously; poll GetErasureStatus with the returned ID.
+func (c *Client) RequestErasure(subjectID string, opts ...RequestOption) (*ErasureRequest, error) {
+	if subjectID == "" {
+		return nil, fmt.Errorf("sandarb: subject_id is required for RequestErasure")
+	}
+	var out ErasureRequest
+	if _, err := c.call(&apiRequest{op: "RequestErasure", feature: FeatureErasure, method: http.MethodPost, path: "/api/erasure", opts: opts}, map[string]string{"subject_id": subjectID}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetErasureStatus returns the current state of an erasure request.
+func (c *Client) GetErasureStatus(requestID string, opts ...RequestOption) (*ErasureRequest, error) {
+	if requestID == "" {
+		return nil, fmt.Errorf("sandarb: request id is required for GetErasureStatus")
+	}
+	var out ErasureRequest
+	if _, err := c.call(&apiRequest{op: "GetErasureStatus", feature: FeatureErasure, method: http.MethodGet, path: "/api/erasure/" + url.PathEscape(requestID), opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,95 @@
+package sandarb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ReportFormat is the encoding of an audit report export.
+type ReportFormat string
+
+const (
+	ReportCSV    ReportFormat = "csv"
+	ReportNDJSON ReportFormat = "ndjson"
+	// ReportPDFManifest is a JSON manifest listing the rendered PDF evidence documents and their digests.
+	ReportPDFManifest ReportFormat = "pdf-manifest"
+)
+
+// AuditFilter selects access log entries for ExportAuditReport. From and To are required;
+// the other fields narrow the report when set.
+type AuditFilter struct {
+	From, To     time.Time
+	AgentIDs     []string
+	ContextNames []string
+	PromptNames  []string
+	TraceID      string
+}
+
+// ExportResult summarizes a completed export.
+type ExportResult struct {
+	Format      ReportFormat
+	ContentType string
+	Bytes       int64
+	// SHA256 is the hex digest of the bytes written, for evidence packages.
+	SHA256   string
+	ServedBy string
+}
+
+// ExportAuditReport streams access logs matching filter to w in the given format. When the server
+// reports a digest (X-Report-SHA256) it is checked against the bytes received. Large exports are
+// not bound by HTTPClient.Timeout; use WithRequestTimeout or WithContext to limit them.
+func (c *Client) ExportAuditReport(w io.Writer, filter AuditFilter, format ReportFormat, opts ...RequestOption) (*ExportResult, error) {
+	switch format {
+	case ReportCSV, ReportNDJSON, ReportPDFManifest:
+	default:
+		return nil, fmt.Errorf("sandarb: unsupported report format %q", format)
+	}
+	if filter.From.IsZero() || filter.To.IsZero() || !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("sandarb: a time range with From before To is required for ExportAuditReport")
+	}
+	if err := c.requireFeature(FeatureAuditExport, opts); err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("format", string(format))
+	q.Set("from", filter.From.UTC().Format(time.RFC3339))
+	q.Set("to", filter.To.UTC().Format(time.RFC3339))
+	for _, a := range filter.AgentIDs {
+		q.Add("agent_id", a)
+	}
+	for _, n := range filter.ContextNames {
+		q.Add("context", n)
+	}
+	for _, n := range filter.PromptNames {
+		q.Add("prompt", n)
+	}
+	if filter.TraceID != "" {
+		q.Set("trace_id", filter.TraceID)
+	}
+	resp, servedBy, err := c.send(&apiRequest{op: "ExportAuditReport", method: http.MethodGet, path: "/api/audit/export?" + q.Encode(), opts: opts, stream: true})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(w, h), resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	out := &ExportResult{
+		Format:      format,
+		ContentType: resp.Header.Get("Content-Type"),
+		Bytes:       n,
+		SHA256:      hex.EncodeToString(h.Sum(nil)),
+		ServedBy:    servedBy,
+	}
+	if want := resp.Header.Get("X-Report-SHA256"); want != "" && want != out.SHA256 {
+		return out, fmt.Errorf("sandarb: report digest mismatch (server %s, received %s)", want, out.SHA256)
+	}
+	return out, nil
+}
@@ -0,0 +1,47 @@
+package sandarb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExportAuditReportOutlivesClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/capabilities" {
+			w.Write([]byte(`{"success":true,"data":{"api_version":"1","features":["audit.export"]}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("agent_id,trace_id\n"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(300 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte("agent,t1\n"))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithTimeout(100*time.Millisecond))
+	filter := AuditFilter{From: time.Now().Add(-time.Hour), To: time.Now()}
+	var buf bytes.Buffer
+	res, err := c.ExportAuditReport(&buf, filter, ReportCSV)
+	if err != nil {
+		t.Fatalf("export cut off by the client timeout: %v", err)
+	}
+	if buf.String() != "agent_id,trace_id\nagent,t1\n" || res.Bytes != int64(buf.Len()) {
+		t.Errorf("export = %q (%d bytes)", buf.String(), res.Bytes)
+	}
+
+	buf.Reset()
+	_, err = c.ExportAuditReport(&buf, filter, ReportCSV, WithRequestTimeout(100*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("export with WithRequestTimeout: error = %v, want a deadline error", err)
+	}
+}
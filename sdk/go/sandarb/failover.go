@@ -0,0 +1,181 @@
+package sandarb
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailoverThreshold   = 3
+	defaultHealthCheckInterval = 30 * time.Second
+)
+
+// WithFallbackURLs sets secondary base URLs (in priority order) used when the primary endpoint
+// fails repeatedly. Unhealthy endpoints are health-checked and the client fails back automatically.
+// A request that fails on one endpoint is re-sent to the next only if that cannot duplicate it:
+// GET, HEAD, OPTIONS, PUT, and DELETE requests, or others carrying an Idempotency-Key header
+// (see WithHeader). Other writes, such as LogActivity, return the endpoint's error instead.
+func WithFallbackURLs(urls []string) ClientOption {
+	return func(c *Client) {
+		c.FallbackURLs = append([]string(nil), urls...)
+	}
+}
+
+// WithFailoverThreshold sets how many consecutive failures mark an endpoint unhealthy (default 3).
+func WithFailoverThreshold(n int) ClientOption {
+	return func(c *Client) { c.failoverThreshold = n }
+}
+
+// WithHealthCheckInterval sets how often unhealthy endpoints are probed for fail-back (default 30s).
+func WithHealthCheckInterval(d time.Duration) ClientOption {
+	return func(c *Client) { c.healthCheckInterval = d }
+}
+
+// endpoint is one base URL with its health state.
+type endpoint struct {
+	base      string
+	failures  int
+	healthy   bool
+	nextProbe time.Time
+	probing   bool
+}
+
+// endpointPool orders endpoints by priority and tracks their health.
+type endpointPool struct {
+	mu            sync.Mutex
+	eps           []*endpoint
+	threshold     int
+	probeInterval time.Duration
+}
+
+func newEndpointPool(bases []string, threshold int, probeInterval time.Duration) *endpointPool {
+	if threshold <= 0 {
+		threshold = defaultFailoverThreshold
+	}
+	if probeInterval <= 0 {
+		probeInterval = defaultHealthCheckInterval
+	}
+	p := &endpointPool{threshold: threshold, probeInterval: probeInterval}
+	seen := make(map[string]bool)
+	for _, b := range bases {
+		b = strings.TrimRight(b, "/")
+		if b == "" || seen[b] {
+			continue
+		}
+		seen[b] = true
+		p.eps = append(p.eps, &endpoint{base: b, healthy: true})
+	}
+	return p
+}
+
+// serves reports whether the pool was built for the same primary endpoint as bases.
+func (p *endpointPool) serves(bases []string) bool {
+	if len(bases) == 0 || len(p.eps) == 0 {
+		return len(bases) == 0 && len(p.eps) == 0
+	}
+	return p.eps[0].base == strings.TrimRight(bases[0], "/")
+}
+
+// candidates returns healthy endpoints in priority order followed by unhealthy ones as a last resort.
+// Unhealthy endpoints due for a probe are returned in probe so the caller can health-check them.
+func (p *endpointPool) candidates() (ordered []*endpoint, probe []*endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	var unhealthy []*endpoint
+	for _, ep := range p.eps {
+		if ep.healthy {
+			ordered = append(ordered, ep)
+			continue
+		}
+		unhealthy = append(unhealthy, ep)
+		if !ep.probing && now.After(ep.nextProbe) {
+			ep.probing = true
+			probe = append(probe, ep)
+		}
+	}
+	return append(ordered, unhealthy...), probe
+}
+
+func (p *endpointPool) success(ep *endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ep.failures = 0
+	ep.healthy = true
+}
+
+func (p *endpointPool) failure(ep *endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ep.failures++
+	if ep.healthy && ep.failures >= p.threshold {
+		ep.healthy = false
+		ep.nextProbe = time.Now().Add(p.probeInterval)
+	}
+}
+
+func (p *endpointPool) probeDone(ep *endpoint, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ep.probing = false
+	if ok {
+		ep.failures = 0
+		ep.healthy = true
+		return
+	}
+	ep.nextProbe = time.Now().Add(p.probeInterval)
+}
+
+// endpoints returns the client's endpoint pool, building it on first use.
+func (c *Client) endpoints() *endpointPool {
+	c.poolMu.Lock()
+	defer c.poolMu.Unlock()
+	bases := c.baseURLs()
+	if c.pool == nil || !c.pool.serves(bases) {
+		c.pool = newEndpointPool(bases, c.failoverThreshold, c.healthCheckInterval)
+	}
+	return c.pool
+}
+
+// probe health-checks an unhealthy endpoint in the background so it can be failed back to.
+// Probes are tracked by the client's lifecycle, so Close cancels and waits for them.
+func (c *Client) probe(p *endpointPool, ep *endpoint) {
+	started := c.goBackground(func(ctx context.Context) {
+		ok := false
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ep.base+"/api/health", nil)
+		if err == nil {
+			resp, err := c.httpClient().Do(req)
+			if err == nil {
+				resp.Body.Close()
+				ok = resp.StatusCode >= 200 && resp.StatusCode < 300
+			}
+		}
+		p.probeDone(ep, ok)
+	})
+	if !started {
+		p.probeDone(ep, false)
+	}
+}
+
+// failoverSafe reports whether a request may be re-sent to another endpoint after a failure.
+// The failed endpoint may already have processed it, so only idempotent methods and requests
+// carrying an Idempotency-Key qualify.
+func failoverSafe(method string, h http.Header) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return h.Get("Idempotency-Key") != ""
+}
+
+// isEndpointFailure reports whether err indicates the endpoint itself is unavailable
+// (transport error or 5xx), as opposed to a request-level error.
+func isEndpointFailure(err error) bool {
+	if se, ok := err.(*SandarbError); ok {
+		return se.StatusCode >= 500
+	}
+	return err != nil
+}
@@ -0,0 +1,104 @@
+package sandarb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// bodyPool recycles response buffers for the raw context paths.
+var bodyPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
+// maxPooledBuffer keeps unusually large bodies from pinning memory in the pool.
+const maxPooledBuffer = 4 << 20
+
+// envelopeMarker identifies bodies that may hold an encrypted envelope.
+var envelopeMarker = []byte(`"` + envelopeKey + `"`)
+
+// GetContextJSON fetches a context and appends its JSON body to dst, skipping the generic map
+// decoding done by GetContext. Reusing dst across calls (e.g. dst[:0]) avoids per-call
+// allocation of the content. The returned result carries version, schema, and serving metadata;
+// its Content is nil. Encrypted or migrated contexts take the GetContext path and are re-encoded.
+func (c *Client) GetContextJSON(ctxName, agentID string, dst []byte, opts ...RequestOption) ([]byte, *GetContextResult, error) {
+	var out []byte
+	res, err := c.getContextFast(ctxName, agentID, opts, func(body []byte) error {
+		out = append(dst, body...)
+		return nil
+	}, func(content map[string]interface{}) error {
+		b, err := json.Marshal(content)
+		out = append(dst, b...)
+		return err
+	})
+	return out, res, err
+}
+
+// GetContextInto fetches a context and decodes it directly into v (typically a pointer to a
+// struct), skipping the intermediate map built by GetContext. The returned result's Content is nil.
+func (c *Client) GetContextInto(ctxName, agentID string, v interface{}, opts ...RequestOption) (*GetContextResult, error) {
+	return c.getContextFast(ctxName, agentID, opts, func(body []byte) error {
+		if err := json.Unmarshal(body, v); err != nil {
+			return fmt.Errorf("sandarb: decode context %q: %w", ctxName, err)
+		}
+		return nil
+	}, func(content map[string]interface{}) error {
+		b, err := json.Marshal(content)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(b, v); err != nil {
+			return fmt.Errorf("sandarb: decode context %q: %w", ctxName, err)
+		}
+		return nil
+	})
+}
+
+// getContextFast reads the /api/inject body into a pooled buffer and passes it to raw. Bodies
+// that need client-side processing (decryption, migrations) are decoded as GetContext would and
+// passed to decoded instead.
+func (c *Client) getContextFast(ctxName, agentID string, opts []RequestOption, raw func([]byte) error, decoded func(map[string]interface{}) error) (*GetContextResult, error) {
+	traceID := traceIDOr(opts, newTraceID)
+	path := "/api/inject?name=" + url.QueryEscape(ctxName) + "&format=json"
+	r := &apiRequest{op: "GetContext", method: http.MethodGet, path: path, agentID: agentID, traceID: traceID, opts: opts}
+	began := time.Now()
+	resp, servedBy, err := c.send(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	buf := bodyPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			bodyPool.Put(buf)
+		}
+	}()
+	if _, err := io.Copy(buf, resp.Body); err != nil {
+		c.reportFailure(r, err, servedBy, time.Since(began))
+		return nil, err
+	}
+	body := buf.Bytes()
+	versionID, schemaVersion := contextHeaders(resp)
+	if !bytes.Contains(body, envelopeMarker) && !c.hasMigration(ctxName, schemaVersion) {
+		res := &GetContextResult{ContextVersionID: versionID, SchemaVersion: schemaVersion, ServedBy: servedBy, Region: resp.Header.Get(regionHeader), meta: responseMetaFrom(resp.Header, servedBy)}
+		return res, raw(body)
+	}
+	var content map[string]interface{}
+	if err := json.Unmarshal(body, &content); err != nil {
+		c.reportFailure(r, err, servedBy, time.Since(began))
+		return nil, err
+	}
+	full, err := c.contextFromResponse(ctxName, content, resp, servedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := decoded(full.Content); err != nil {
+		return nil, err
+	}
+	full.Content, full.raw = nil, nil
+	return full, nil
+}
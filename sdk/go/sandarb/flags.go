@@ -0,0 +1,265 @@
+package sandarb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultFlagTTL is how long fetched flags are reused when not streaming.
+const defaultFlagTTL = 30 * time.Second
+
+// Flag is a feature flag evaluated for one agent.
+type Flag struct {
+	Key     string      `json:"key"`
+	Enabled bool        `json:"enabled"`
+	Variant string      `json:"variant,omitempty"`
+	Value   interface{} `json:"value,omitempty"`
+	// Deleted marks a streamed update removing the flag.
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// FlagSet is the flags evaluated for an agent.
+type FlagSet struct {
+	AgentID   string          `json:"agent_id"`
+	Version   int64           `json:"version"`
+	Flags     map[string]Flag `json:"flags"`
+	FetchedAt time.Time       `json:"-"`
+}
+
+// Enabled reports whether key is present and enabled.
+func (fs *FlagSet) Enabled(key string) bool {
+	f, ok := fs.Flags[key]
+	return ok && f.Enabled
+}
+
+// WithFlagCacheTTL sets how long flags from GetFlags are reused before refetching (default 30s).
+func WithFlagCacheTTL(d time.Duration) ClientOption {
+	return func(c *Client) { c.flagTTL = d }
+}
+
+// WithFlagStreaming keeps each agent's flags current over a server-sent event stream, started on
+// the first GetFlags for the agent. Once a connected stream has delivered a snapshot, cached flags
+// don't expire; if it drops, the cache TTL applies until it reconnects and sends a new snapshot.
+func WithFlagStreaming() ClientOption {
+	return func(c *Client) { c.flagStreaming = true }
+}
+
+type flagState struct {
+	mu        sync.Mutex
+	set       *FlagSet
+	streaming bool // stream connected and a snapshot applied
+	watching  bool // watcher goroutine started
+}
+
+// GetFlags returns the feature flags evaluated for agentID, from cache when fresh.
+func (c *Client) GetFlags(agentID string, opts ...RequestOption) (*FlagSet, error) {
+	if agentID == "" {
+		agentID = c.AgentID
+	}
+	if agentID == "" {
+		return nil, fmt.Errorf("sandarb: agent_id is required for GetFlags (or set SANDARB_AGENT_ID)")
+	}
+	v, _ := c.flags.LoadOrStore(agentID, &flagState{})
+	st := v.(*flagState)
+	ttl := c.flagTTL
+	if ttl <= 0 {
+		ttl = defaultFlagTTL
+	}
+	st.mu.Lock()
+	if st.set != nil && (st.streaming || time.Since(st.set.FetchedAt) < ttl) {
+		out := st.set.clone()
+		st.mu.Unlock()
+		if c.Metrics != nil {
+			c.Metrics.CacheHit("GetFlags")
+		}
+		return out, nil
+	}
+	st.mu.Unlock()
+	var fs FlagSet
+	path := "/api/flags?agent_id=" + url.QueryEscape(agentID)
+	if _, err := c.call(&apiRequest{op: "GetFlags", feature: FeatureFlags, method: http.MethodGet, path: path, agentID: agentID, opts: opts}, nil, &fs); err != nil {
+		return nil, err
+	}
+	fs.AgentID, fs.FetchedAt = agentID, time.Now()
+	if fs.Flags == nil {
+		fs.Flags = make(map[string]Flag)
+	}
+	st.mu.Lock()
+	if st.set == nil || fs.Version >= st.set.Version {
+		st.set = &fs
+	}
+	out := st.set.clone()
+	startWatch := c.flagStreaming && !st.watching
+	st.watching = st.watching || startWatch
+	st.mu.Unlock()
+	if startWatch {
+		c.goBackground(func(ctx context.Context) { c.watchFlags(ctx, agentID, st) })
+	}
+	return out, nil
+}
+
+// IsEnabled reports whether flag is enabled for agentID. Flags that are unknown, or can't be
+// fetched, are reported disabled.
+func (c *Client) IsEnabled(flag, agentID string, opts ...RequestOption) bool {
+	fs, err := c.GetFlags(agentID, opts...)
+	return err == nil && fs.Enabled(flag)
+}
+
+func (fs *FlagSet) clone() *FlagSet {
+	out := *fs
+	out.Flags = make(map[string]Flag, len(fs.Flags))
+	for k, f := range fs.Flags {
+		out.Flags[k] = f
+	}
+	return &out
+}
+
+// watchFlags applies streamed flag events for agentID until ctx is canceled, reconnecting with backoff.
+func (c *Client) watchFlags(ctx context.Context, agentID string, st *flagState) {
+	backoff := time.Second
+	for {
+		connected, _ := c.streamFlags(ctx, agentID, st)
+		st.mu.Lock()
+		st.streaming = false
+		st.mu.Unlock()
+		if connected {
+			backoff = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// streamFlags reads one event stream connection. Events are "snapshot" (a FlagSet) and
+// "update" (a Flag); connected reports whether the stream was established. Like other GET
+// requests it fails over across endpoints and is subject to the residency check.
+func (c *Client) streamFlags(ctx context.Context, agentID string, st *flagState) (connected bool, err error) {
+	pool := c.endpoints()
+	if len(pool.eps) == 0 && c.region != "" {
+		return false, &ResidencyError{Region: c.region}
+	}
+	eps, _ := pool.candidates()
+	hc := *c.httpClient()
+	hc.Timeout = 0 // the stream is long-lived; ctx ends it
+	lastErr := fmt.Errorf("sandarb: no base URL configured")
+	for _, ep := range eps {
+		resp, err := c.openFlagStream(ctx, &hc, ep.base, agentID)
+		if err != nil {
+			if !isEndpointFailure(err) {
+				pool.success(ep)
+				return false, err
+			}
+			if ctx.Err() != nil {
+				return false, err
+			}
+			pool.failure(ep)
+			lastErr = err
+			continue
+		}
+		pool.success(ep)
+		defer resp.Body.Close()
+		if err := c.checkResidency(resp, ep.base); err != nil {
+			return false, err
+		}
+		return true, c.readFlagStream(resp, agentID, st)
+	}
+	return false, lastErr
+}
+
+// openFlagStream connects to base's flag stream, returning non-200 responses as *SandarbError.
+func (c *Client) openFlagStream(ctx context.Context, hc *http.Client, base, agentID string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/api/flags/stream?agent_id="+url.QueryEscape(agentID), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.headers(agentID, "") {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.signer != nil {
+		if err := c.signer.sign(req); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &SandarbError{Message: "flag stream: " + resp.Status, StatusCode: resp.StatusCode, Meta: responseMetaFrom(resp.Header, base)}
+	}
+	return resp, nil
+}
+
+// readFlagStream applies the events of a connected stream until it ends. The cached flags are
+// only treated as streamed (and stop expiring) once a snapshot has been applied.
+func (c *Client) readFlagStream(resp *http.Response, agentID string, st *flagState) error {
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	event, data := "", strings.Builder{}
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			c.applyFlagEvent(agentID, st, event, data.String())
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	return sc.Err()
+}
+
+func (c *Client) applyFlagEvent(agentID string, st *flagState, event, data string) {
+	if data == "" {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	switch event {
+	case "snapshot":
+		var fs FlagSet
+		if json.Unmarshal([]byte(data), &fs) != nil {
+			return
+		}
+		if fs.Flags == nil {
+			fs.Flags = make(map[string]Flag)
+		}
+		fs.AgentID, fs.FetchedAt = agentID, time.Now()
+		st.set = &fs
+		st.streaming = true
+	case "update", "":
+		var f Flag
+		if json.Unmarshal([]byte(data), &f) != nil || f.Key == "" || st.set == nil {
+			return
+		}
+		next := st.set.clone()
+		if f.Deleted {
+			delete(next.Flags, f.Key)
+		} else {
+			next.Flags[f.Key] = f
+		}
+		next.FetchedAt = time.Now()
+		st.set = next
+	}
+}
@@ -0,0 +1,82 @@
+package sandarb
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// flightGroup coalesces concurrent calls with the same key into one execution (singleflight).
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+
+	onWait func() // test hook, called when a caller starts waiting on another's call
+}
+
+type flightCall struct {
+	done chan struct{}
+	val  interface{}
+	err  error
+}
+
+// do runs fn once for all concurrent callers of key. shared is false for the caller that ran fn.
+// A caller waiting on another's fn stops waiting when its own ctx ends, returning ctx.Err().
+func (g *flightGroup) do(ctx context.Context, key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		if g.onWait != nil {
+			g.onWait()
+		}
+		select {
+		case <-call.done:
+			return call.val, call.err, true
+		case <-ctx.Done():
+			return nil, ctx.Err(), true
+		}
+	}
+	call := &flightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.val, call.err = fn()
+	return call.val, call.err, false
+}
+
+// WithoutRequestCoalescing disables coalescing of concurrent identical GetContext and GetPrompt
+// calls. By default, while a fetch for the same context (name and agent) or prompt (name, agent
+// and variables) is in flight, further callers wait for its result instead of issuing their own
+// request; the access log then records the first caller's trace ID.
+func WithoutRequestCoalescing() ClientOption {
+	return func(c *Client) { c.noCoalesce = true }
+}
+
+// coalesce runs fetch through the client's flight group under key, unless coalescing is off or
+// opts customize the request (headers, query, metadata capture, a timeout or a token budget). The
+// value may be shared between callers, who must copy it before changing it. A waiter gives up when
+// its own context ends, and one whose leader failed only because the leader's context ended
+// retries with its own context.
+func (c *Client) coalesce(key string, opts []RequestOption, fetch func() (interface{}, error)) (interface{}, bool, error) {
+	ro := newRequestOptions(opts)
+	if c.noCoalesce || c.dryRun != nil || ro.header != nil || ro.query != nil || ro.meta != nil ||
+		ro.timeout != 0 || ro.tokenBudget != nil {
+		v, err := fetch()
+		return v, false, err
+	}
+	v, err, shared := c.inflight.do(ro.ctx, key, fetch)
+	if shared && err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) && ro.ctx.Err() == nil {
+		v, err = fetch()
+		return v, false, err
+	}
+	return v, shared, err
+}
@@ -0,0 +1,202 @@
+package sandarb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gatedServer answers prompt pulls and context fetches once release is closed, so concurrent
+// callers overlap in flight. Each request is announced on arrived before it blocks.
+func gatedServer(t *testing.T, release, arrived chan struct{}, requests *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		arrived <- struct{}{}
+		<-release
+		if strings.HasPrefix(r.URL.Path, "/api/prompts/pull") {
+			w.Write([]byte(`{"success":true,"data":{"content":"Hello Ada","version":3,"model":"gpt-4"}}`))
+			return
+		}
+		w.Write([]byte(`{"limit":10}`))
+	}))
+	t.Cleanup(srv.Close)
+	// Runs before srv.Close, so a failed test doesn't leave handlers blocked.
+	t.Cleanup(func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	})
+	return srv
+}
+
+// receive waits for n signals on ch, failing the test if they don't come.
+func receive(t *testing.T, ch chan struct{}, n int, what string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < n; i++ {
+		select {
+		case <-ch:
+		case <-timeout:
+			t.Fatalf("saw %d of %d %s", i, n, what)
+		}
+	}
+}
+
+// waitingClient returns c with its flight group announcing each caller that waits on another's fetch.
+func waitingClient(c *Client) (*Client, chan struct{}) {
+	waiting := make(chan struct{}, 16)
+	c.inflight.onWait = func() { waiting <- struct{}{} }
+	return c, waiting
+}
+
+func TestCoalescedGetPromptCallersOwnResults(t *testing.T) {
+	release, arrived := make(chan struct{}), make(chan struct{}, 16)
+	var pulls int32
+	srv := gatedServer(t, release, arrived, &pulls)
+	c, waiting := waitingClient(NewClient(WithBaseURL(srv.URL), WithAgentID("agent"), WithPromptCache(PromptCacheOptions{}), WithoutVariableValidation()))
+
+	vars := map[string]interface{}{"name": "Ada"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := c.GetPrompt("greet", vars, "", "")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if r.Content != "Hello Ada" {
+				t.Errorf("Content = %q, want %q", r.Content, "Hello Ada")
+			}
+			r.Content, r.Tokens = "mutated", &TokenUsage{}
+		}()
+	}
+	receive(t, arrived, 1, "pulls")
+	receive(t, waiting, 7, "waiting callers")
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&pulls); n != 1 {
+		t.Errorf("server saw %d pulls, want 1", n)
+	}
+	if r, ok := c.promptCache.get(mustPromptCacheKey(t, "agent", "greet", vars)); !ok || r.Content != "Hello Ada" {
+		t.Errorf("cached prompt = %+v, %v", r, ok)
+	}
+}
+
+func TestGetPromptWithTokenBudgetIsNotCoalesced(t *testing.T) {
+	release, arrived := make(chan struct{}), make(chan struct{}, 16)
+	var pulls int32
+	srv := gatedServer(t, release, arrived, &pulls)
+	c := NewClient(WithBaseURL(srv.URL), WithAgentID("agent"), WithoutVariableValidation())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := c.GetPrompt("greet", nil, "", "", WithTokenBudget(TokenBudget{OutputTokens: 100}))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if r.Tokens == nil || r.Tokens.Output != 100 || r.Tokens.Window != 8192 {
+				t.Errorf("Tokens = %+v", r.Tokens)
+			}
+		}()
+	}
+	receive(t, arrived, 4, "pulls")
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&pulls); n != 4 {
+		t.Errorf("server saw %d pulls, want 4", n)
+	}
+}
+
+func TestCoalescedGetContextCallersOwnContent(t *testing.T) {
+	release, arrived := make(chan struct{}), make(chan struct{}, 16)
+	var fetches int32
+	srv := gatedServer(t, release, arrived, &fetches)
+	c, waiting := waitingClient(NewClient(WithBaseURL(srv.URL)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := c.GetContext("limits", "agent")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if r.Content["limit"] != float64(10) {
+				t.Errorf("Content = %v", r.Content)
+			}
+			r.Content["limit"] = 0
+		}()
+	}
+	receive(t, arrived, 1, "fetches")
+	receive(t, waiting, 7, "waiting callers")
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("server saw %d fetches, want 1", n)
+	}
+}
+
+func TestCoalescedWaiterHonorsItsDeadline(t *testing.T) {
+	release, arrived := make(chan struct{}), make(chan struct{}, 16)
+	var fetches int32
+	srv := gatedServer(t, release, arrived, &fetches)
+	c, waiting := waitingClient(NewClient(WithBaseURL(srv.URL)))
+
+	leader := make(chan error, 1)
+	go func() {
+		_, err := c.GetContext("limits", "agent")
+		leader <- err
+	}()
+	receive(t, arrived, 1, "fetches")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := c.GetContext("limits", "agent", WithContext(ctx))
+		waiter <- err
+	}()
+	receive(t, waiting, 1, "waiting callers")
+	select {
+	case err := <-waiter:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("waiter error = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter still blocked on the leader after its deadline")
+	}
+
+	close(release)
+	if err := <-leader; err != nil {
+		t.Errorf("leader error = %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("server saw %d fetches, want 1", n)
+	}
+}
+
+func mustPromptCacheKey(t *testing.T, agentID, name string, vars map[string]interface{}) string {
+	t.Helper()
+	k, ok := promptCacheKey(agentID, name, 0, vars)
+	if !ok {
+		t.Fatal("variables not cacheable")
+	}
+	return k
+}
@@ -0,0 +1,102 @@
+package sandarb
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ContextFormat is the rendering requested from /api/inject.
+type ContextFormat string
+
+const (
+	FormatJSON     ContextFormat = "json"
+	FormatYAML     ContextFormat = "yaml"
+	FormatMarkdown ContextFormat = "markdown"
+	FormatText     ContextFormat = "text"
+)
+
+// RawContextResult is the undecoded body of a context fetch in the requested format.
+type RawContextResult struct {
+	Body             []byte
+	ContentType      string
+	Format           ContextFormat
+	ContextVersionID *string
+	ServedBy         string
+	Region           string
+
+	meta ResponseMeta
+}
+
+// TextContextResult is a context rendered as text (markdown, plain text, or YAML).
+type TextContextResult struct {
+	Text             string
+	Format           ContextFormat
+	ContextVersionID *string
+	ServedBy         string
+	Region           string
+
+	meta ResponseMeta
+}
+
+// GetContextRaw fetches context by name in the given format and returns the body as-is,
+// with its content type, so non-JSON contexts are usable. An empty format means FormatJSON.
+func (c *Client) GetContextRaw(ctxName, agentID string, format ContextFormat, opts ...RequestOption) (*RawContextResult, error) {
+	if format == "" {
+		format = FormatJSON
+	}
+	if format == FormatMarkdown {
+		if err := c.requireFeature(FeatureContextMarkdown, opts); err != nil {
+			return nil, err
+		}
+	}
+	traceID := traceIDOr(opts, newTraceID)
+	path := "/api/inject?name=" + url.QueryEscape(ctxName) + "&format=" + url.QueryEscape(string(format))
+	r := &apiRequest{op: "GetContextRaw", method: http.MethodGet, path: path, agentID: agentID, traceID: traceID, opts: opts}
+	began := time.Now()
+	resp, servedBy, err := c.send(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.reportFailure(r, err, servedBy, time.Since(began))
+		return nil, err
+	}
+	out := &RawContextResult{
+		Body:        body,
+		ContentType: resp.Header.Get("Content-Type"),
+		Format:      format,
+		ServedBy:    servedBy,
+		Region:      resp.Header.Get(regionHeader),
+		meta:        responseMetaFrom(resp.Header, servedBy),
+	}
+	if v := resp.Header.Get("X-Context-Version-ID"); v != "" {
+		out.ContextVersionID = &v
+	}
+	return out, nil
+}
+
+// GetContextText fetches context by name rendered as FormatMarkdown, FormatText, or FormatYAML.
+func (c *Client) GetContextText(ctxName, agentID string, format ContextFormat, opts ...RequestOption) (*TextContextResult, error) {
+	switch format {
+	case FormatMarkdown, FormatText, FormatYAML:
+	default:
+		return nil, fmt.Errorf("sandarb: GetContextText does not support format %q (use GetContext for JSON)", format)
+	}
+	raw, err := c.GetContextRaw(ctxName, agentID, format, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TextContextResult{
+		Text:             string(raw.Body),
+		Format:           raw.Format,
+		ContextVersionID: raw.ContextVersionID,
+		ServedBy:         raw.ServedBy,
+		Region:           raw.Region,
+		meta:             raw.meta,
+	}, nil
+}
@@ -0,0 +1,457 @@
+// Package gitsync reconciles a directory of prompt and context definitions with a Sandarb server,
+// so governance artifacts can live in git and be rolled out with a reviewed plan:
+//
+//	defs, err := gitsync.Load("governance")
+//	plan, err := defs.Plan(client)
+//	plan.WriteTo(os.Stdout)
+//	result, err := plan.Apply(client, gitsync.ApplyOptions{CommitMessage: "sync " + gitSHA})
+//
+// Definitions are YAML or JSON files, one resource per file, named after the resource unless
+// they set name explicitly:
+//
+//	governance/contexts/kyc-policy.yaml
+//	governance/prompts/support-agent.yaml
+//
+// A context file has description, tags, data_classification, regulatory_hooks, and content (a
+// mapping); a prompt file has description, tags, content, system_prompt, and model.
+//
+// Content is compared with each resource's newest revision that was not rejected, including
+// revisions still pending approval, so applying the same directory twice changes nothing the
+// second time. Apply creates and updates only; resources without a definition are left alone.
+// Tags and prompt descriptions are set when a resource is created and are not updated.
+package gitsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb"
+)
+
+// ContextDef is a context definition file.
+type ContextDef struct {
+	Name               string                 `json:"name"`
+	Description        string                 `json:"description,omitempty"`
+	Tags               []string               `json:"tags,omitempty"`
+	DataClassification string                 `json:"data_classification,omitempty"`
+	RegulatoryHooks    []string               `json:"regulatory_hooks,omitempty"`
+	Content            map[string]interface{} `json:"content"`
+	// File is the path the definition was loaded from.
+	File string `json:"-"`
+}
+
+// PromptDef is a prompt definition file.
+type PromptDef struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Content      string   `json:"content"`
+	SystemPrompt *string  `json:"system_prompt,omitempty"`
+	Model        *string  `json:"model,omitempty"`
+	// File is the path the definition was loaded from.
+	File string `json:"-"`
+}
+
+// Definitions is the desired state read from a definitions directory.
+type Definitions struct {
+	Contexts []ContextDef
+	Prompts  []PromptDef
+}
+
+// Load reads dir/contexts and dir/prompts. Either subdirectory may be absent, but not both.
+// Files other than .yaml, .yml, and .json are ignored.
+func Load(dir string) (*Definitions, error) {
+	d := &Definitions{}
+	found := false
+	for _, kind := range []string{"contexts", "prompts"} {
+		entries, err := os.ReadDir(filepath.Join(dir, kind))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sandarb: %w", err)
+		}
+		found = true
+		seen := make(map[string]string)
+		for _, e := range entries {
+			ext := strings.ToLower(filepath.Ext(e.Name()))
+			if e.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+				continue
+			}
+			file := filepath.Join(dir, kind, e.Name())
+			stem := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+			var name string
+			if kind == "contexts" {
+				def := ContextDef{Name: stem, File: file}
+				if err := readDef(file, &def); err != nil {
+					return nil, err
+				}
+				if def.Content == nil {
+					return nil, fmt.Errorf("sandarb: %s: content is required", file)
+				}
+				name = def.Name
+				d.Contexts = append(d.Contexts, def)
+			} else {
+				def := PromptDef{Name: stem, File: file}
+				if err := readDef(file, &def); err != nil {
+					return nil, err
+				}
+				if def.Content == "" {
+					return nil, fmt.Errorf("sandarb: %s: content is required", file)
+				}
+				name = def.Name
+				d.Prompts = append(d.Prompts, def)
+			}
+			if name == "" {
+				return nil, fmt.Errorf("sandarb: %s: name is empty", file)
+			}
+			if prev, ok := seen[name]; ok {
+				return nil, fmt.Errorf("sandarb: %s: %s %q is already defined in %s", file, strings.TrimSuffix(kind, "s"), name, prev)
+			}
+			seen[name] = file
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("sandarb: no contexts or prompts directory in %s", dir)
+	}
+	sort.Slice(d.Contexts, func(i, j int) bool { return d.Contexts[i].Name < d.Contexts[j].Name })
+	sort.Slice(d.Prompts, func(i, j int) bool { return d.Prompts[i].Name < d.Prompts[j].Name })
+	return d, nil
+}
+
+// readDef decodes a YAML or JSON definition into dst, rejecting unknown fields so typos in
+// field names are caught before anything is applied.
+func readDef(file string, dst interface{}) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("sandarb: %w", err)
+	}
+	if ext := strings.ToLower(filepath.Ext(file)); ext == ".yaml" || ext == ".yml" {
+		v, err := decodeYAML(data)
+		if err != nil {
+			return fmt.Errorf("sandarb: %s: %v", file, err)
+		}
+		if _, ok := v.(map[string]interface{}); !ok {
+			return fmt.Errorf("sandarb: %s: expected a mapping at the top level", file)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return fmt.Errorf("sandarb: %s: %v", file, err)
+		}
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return fmt.Errorf("sandarb: %s: %v", file, err)
+	}
+	return nil
+}
+
+// Action is what applying a Change does.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionNone   Action = "none"
+)
+
+// Change is one resource's difference between the definitions and the server.
+type Change struct {
+	Kind   sandarb.ResourceKind
+	Name   string
+	Action Action
+	// Fields lists what an update changes, e.g. "content", "description", "model".
+	Fields []string
+	File   string
+
+	id      string
+	context *ContextDef
+	prompt  *PromptDef
+}
+
+func (ch *Change) changes(field string) bool {
+	for _, f := range ch.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan is the set of changes that would bring the server in line with the definitions.
+type Plan struct {
+	Changes []Change
+}
+
+// HasChanges reports whether applying the plan would change anything.
+func (p *Plan) HasChanges() bool {
+	for _, ch := range p.Changes {
+		if ch.Action != ActionNone {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTo writes the plan as an aligned table, one resource per line.
+func (p *Plan) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tNAME\tACTION\tFIELDS")
+	creates, updates := 0, 0
+	for _, ch := range p.Changes {
+		fields := strings.Join(ch.Fields, ", ")
+		if fields == "" {
+			fields = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", ch.Kind, ch.Name, ch.Action, fields)
+		switch ch.Action {
+		case ActionCreate:
+			creates++
+		case ActionUpdate:
+			updates++
+		}
+	}
+	tw.Flush()
+	fmt.Fprintf(&b, "\nPlan: %d to create, %d to update, %d unchanged.\n", creates, updates, len(p.Changes)-creates-updates)
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Plan compares the definitions with the server and returns the changes needed. It only reads.
+func (d *Definitions) Plan(c *sandarb.Client, opts ...sandarb.RequestOption) (*Plan, error) {
+	p := &Plan{}
+	if len(d.Contexts) > 0 {
+		records, err := c.ListContextRecords(opts...)
+		if err != nil {
+			return nil, err
+		}
+		ids := make(map[string]string, len(records))
+		for _, r := range records {
+			ids[r.Name] = r.ID
+		}
+		for i := range d.Contexts {
+			ch, err := planContext(c, &d.Contexts[i], ids[d.Contexts[i].Name], opts)
+			if err != nil {
+				return nil, err
+			}
+			p.Changes = append(p.Changes, ch)
+		}
+	}
+	if len(d.Prompts) > 0 {
+		records, err := c.ListPromptRecords(opts...)
+		if err != nil {
+			return nil, err
+		}
+		ids := make(map[string]string, len(records))
+		for _, r := range records {
+			ids[r.Name] = r.ID
+		}
+		for i := range d.Prompts {
+			ch, err := planPrompt(c, &d.Prompts[i], ids[d.Prompts[i].Name], opts)
+			if err != nil {
+				return nil, err
+			}
+			p.Changes = append(p.Changes, ch)
+		}
+	}
+	return p, nil
+}
+
+func planContext(c *sandarb.Client, def *ContextDef, id string, opts []sandarb.RequestOption) (Change, error) {
+	ch := Change{Kind: sandarb.ResourceKindContext, Name: def.Name, Action: ActionCreate, File: def.File, id: id, context: def}
+	if id == "" {
+		return ch, nil
+	}
+	rec, err := c.GetContextRecord(id, opts...)
+	if err != nil {
+		return ch, err
+	}
+	revisions, err := c.ListContextRevisions(id, opts...)
+	if err != nil {
+		return ch, err
+	}
+	current := rec.Content
+	for _, r := range revisions {
+		if !strings.EqualFold(r.Status, "rejected") {
+			current = r.Content
+			break
+		}
+	}
+	if def.Description != rec.Description {
+		ch.Fields = append(ch.Fields, "description")
+	}
+	if def.DataClassification != "" && !strings.EqualFold(def.DataClassification, rec.DataClassification) {
+		ch.Fields = append(ch.Fields, "data_classification")
+	}
+	if !sameStrings(def.RegulatoryHooks, rec.RegulatoryHooks) {
+		ch.Fields = append(ch.Fields, "regulatory_hooks")
+	}
+	if !sameJSON(def.Content, current) {
+		ch.Fields = append(ch.Fields, "content")
+	}
+	ch.Action = actionFor(ch.Fields)
+	return ch, nil
+}
+
+func planPrompt(c *sandarb.Client, def *PromptDef, id string, opts []sandarb.RequestOption) (Change, error) {
+	ch := Change{Kind: sandarb.ResourceKindPrompt, Name: def.Name, Action: ActionCreate, File: def.File, id: id, prompt: def}
+	if id == "" {
+		return ch, nil
+	}
+	rec, err := c.GetPromptRecord(id, opts...)
+	if err != nil {
+		return ch, err
+	}
+	var current *sandarb.PromptVersion
+	for i := range rec.Versions {
+		if !strings.EqualFold(rec.Versions[i].Status, "rejected") {
+			current = &rec.Versions[i]
+			break
+		}
+	}
+	switch {
+	case current == nil:
+		ch.Fields = []string{"content"}
+	default:
+		if def.Content != current.Content {
+			ch.Fields = append(ch.Fields, "content")
+		}
+		if !sameOptional(def.SystemPrompt, current.SystemPrompt) {
+			ch.Fields = append(ch.Fields, "system_prompt")
+		}
+		if !sameOptional(def.Model, current.Model) {
+			ch.Fields = append(ch.Fields, "model")
+		}
+	}
+	ch.Action = actionFor(ch.Fields)
+	return ch, nil
+}
+
+func actionFor(fields []string) Action {
+	if len(fields) == 0 {
+		return ActionNone
+	}
+	return ActionUpdate
+}
+
+// ApplyOptions configures Plan.Apply.
+type ApplyOptions struct {
+	// CommitMessage is recorded on every revision and version created.
+	CommitMessage string
+	// AutoApprove activates content changes immediately instead of submitting them for approval.
+	AutoApprove bool
+	CreatedBy   string
+}
+
+// Result reports what Apply did.
+type Result struct {
+	// Applied are the changes made, in plan order.
+	Applied []Change
+}
+
+// Apply makes the plan's changes in order, stopping at the first error; Result lists what was
+// applied before it. Re-running Plan and Apply after a failure picks up where it stopped.
+func (p *Plan) Apply(c *sandarb.Client, ao ApplyOptions, opts ...sandarb.RequestOption) (*Result, error) {
+	ro := sandarb.RevisionOptions{CommitMessage: ao.CommitMessage, AutoApprove: ao.AutoApprove, CreatedBy: ao.CreatedBy}
+	res := &Result{}
+	for _, ch := range p.Changes {
+		if ch.Action == ActionNone {
+			continue
+		}
+		var err error
+		if ch.context != nil {
+			err = applyContext(c, ch, ro, opts)
+		} else {
+			err = applyPrompt(c, ch, ro, opts)
+		}
+		if err != nil {
+			return res, fmt.Errorf("sandarb: %s %q: %w", ch.Kind, ch.Name, err)
+		}
+		res.Applied = append(res.Applied, ch)
+	}
+	return res, nil
+}
+
+func applyContext(c *sandarb.Client, ch Change, ro sandarb.RevisionOptions, opts []sandarb.RequestOption) error {
+	def := ch.context
+	rec := sandarb.ContextRecord{
+		Name:               def.Name,
+		Description:        def.Description,
+		Tags:               def.Tags,
+		DataClassification: def.DataClassification,
+		RegulatoryHooks:    def.RegulatoryHooks,
+		Content:            def.Content,
+	}
+	if ch.Action == ActionCreate {
+		_, err := c.CreateContext(rec, opts...)
+		return err
+	}
+	if ch.changes("description") || ch.changes("data_classification") || ch.changes("regulatory_hooks") {
+		if _, err := c.UpdateContext(ch.id, rec, opts...); err != nil {
+			return err
+		}
+	}
+	if ch.changes("content") {
+		if _, err := c.CreateContextRevision(ch.id, def.Content, ro, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyPrompt(c *sandarb.Client, ch Change, ro sandarb.RevisionOptions, opts []sandarb.RequestOption) error {
+	def := ch.prompt
+	id := ch.id
+	if ch.Action == ActionCreate {
+		rec, err := c.CreatePrompt(sandarb.PromptRecord{Name: def.Name, Description: def.Description, Tags: def.Tags}, opts...)
+		if err != nil {
+			return err
+		}
+		id = rec.ID
+	}
+	_, err := c.CreatePromptVersion(id, sandarb.PromptVersion{Content: def.Content, SystemPrompt: def.SystemPrompt, Model: def.Model}, ro, opts...)
+	return err
+}
+
+// sameJSON compares values by their JSON encoding, so numbers decoded differently compare equal.
+func sameJSON(a, b interface{}) bool {
+	var x, y interface{}
+	ja, err1 := json.Marshal(a)
+	jb, err2 := json.Marshal(b)
+	if err1 != nil || err2 != nil || json.Unmarshal(ja, &x) != nil || json.Unmarshal(jb, &y) != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameOptional treats unset and empty as equal.
+func sameOptional(a, b *string) bool {
+	var x, y string
+	if a != nil {
+		x = *a
+	}
+	if b != nil {
+		y = *b
+	}
+	return x == y
+}
@@ -0,0 +1,440 @@
+package gitsync
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// decodeYAML parses the YAML subset used by definition files into JSON-compatible values:
+// block mappings and sequences, plain and quoted scalars, flow sequences and mappings of
+// scalars, literal (|) and folded (>) block scalars, and comments. Anchors, tags, multiple
+// documents, complex keys and .inf/.nan (which JSON can't represent) are not supported. Errors name the offending line and are wrapped
+// with the sandarb: prefix and file name by readDef.
+func decodeYAML(data []byte) (interface{}, error) {
+	p := &yamlParser{}
+	// A final newline ends the last line rather than starting an empty one.
+	text := strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	// Document markers count only at column 0, where block scalar content can't be.
+	started, ended := false, false
+	for i, raw := range strings.Split(text, "\n") {
+		if i == 0 {
+			raw = strings.TrimPrefix(raw, "\uFEFF")
+		}
+		switch {
+		case strings.TrimSpace(stripComment(raw)) == "":
+		case strings.HasPrefix(raw, "%") && !started:
+			continue
+		case marker(raw, "---") && !started:
+			started = true
+			continue
+		case marker(raw, "...") && !ended:
+			ended = true
+			continue
+		case ended || marker(raw, "---"):
+			return nil, fmt.Errorf("line %d: multiple documents are not supported", i+1)
+		default:
+			started = true
+		}
+		if lead := raw[:len(raw)-len(strings.TrimLeft(raw, " \t"))]; strings.Contains(lead, "\t") && strings.TrimSpace(raw) != "" {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		p.lines = append(p.lines, yamlLine{num: i + 1, raw: raw})
+	}
+	p.skipBlank()
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	v, err := p.node(p.lines[p.pos].indent())
+	if err != nil {
+		return nil, err
+	}
+	p.skipBlank()
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected content", p.lines[p.pos].num)
+	}
+	return v, nil
+}
+
+// marker reports whether line is the document marker m ("---" or "..."), optionally followed by
+// a comment.
+func marker(line, m string) bool {
+	return strings.HasPrefix(line, m) && strings.TrimSpace(stripComment(line[len(m):])) == "" &&
+		(len(line) == len(m) || line[len(m)] == ' ' || line[len(m)] == '\t')
+}
+
+type yamlLine struct {
+	num int
+	raw string
+}
+
+func (l yamlLine) indent() int { return len(l.raw) - len(strings.TrimLeft(l.raw, " ")) }
+
+// text is the line without indentation and trailing comment.
+func (l yamlLine) text() string { return strings.TrimSpace(stripComment(l.raw)) }
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) skipBlank() {
+	for p.pos < len(p.lines) && p.lines[p.pos].text() == "" {
+		p.pos++
+	}
+}
+
+// node parses the block node whose first line is at p.pos with the given indentation.
+func (p *yamlParser) node(indent int) (interface{}, error) {
+	l := p.lines[p.pos]
+	t := l.text()
+	switch {
+	case t == "-" || strings.HasPrefix(t, "- "):
+		return p.sequence(indent)
+	case keySplit(t) >= 0:
+		return p.mapping(indent)
+	default:
+		p.pos++
+		return scalar(t, l.num)
+	}
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	out := []interface{}{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) {
+			return out, nil
+		}
+		l := p.lines[p.pos]
+		t := l.text()
+		if l.indent() != indent || !(t == "-" || strings.HasPrefix(t, "- ")) {
+			if l.indent() > indent {
+				return nil, fmt.Errorf("line %d: bad indentation", l.num)
+			}
+			return out, nil
+		}
+		if t == "-" {
+			p.pos++
+			v, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+			continue
+		}
+		if rest := strings.TrimSpace(t[2:]); rest[0] == '|' || rest[0] == '>' {
+			p.pos++
+			out = append(out, p.blockScalar(rest, indent))
+			continue
+		}
+		// Re-read the item's content as a line indented past the "- ", so an inline
+		// mapping continues with the lines aligned under it.
+		p.lines[p.pos].raw = strings.Repeat(" ", indent+2) + l.raw[indent+2:]
+		v, err := p.node(p.lines[p.pos].indent())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	out := map[string]interface{}{}
+	for {
+		p.skipBlank()
+		if p.pos >= len(p.lines) {
+			return out, nil
+		}
+		l := p.lines[p.pos]
+		if l.indent() < indent {
+			return out, nil
+		}
+		t := l.text()
+		i := keySplit(t)
+		if l.indent() > indent || i < 0 {
+			return nil, fmt.Errorf("line %d: expected a mapping key", l.num)
+		}
+		key, err := scalarString(strings.TrimSpace(t[:i]), l.num)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := out[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", l.num, key)
+		}
+		rest := strings.TrimSpace(t[i+1:])
+		p.pos++
+		switch {
+		case rest == "":
+			// A sequence may sit at the key's own indentation.
+			p.skipBlank()
+			if p.pos < len(p.lines) && p.lines[p.pos].indent() == indent {
+				if nt := p.lines[p.pos].text(); nt == "-" || strings.HasPrefix(nt, "- ") {
+					if out[key], err = p.sequence(indent); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			}
+			if out[key], err = p.nested(indent); err != nil {
+				return nil, err
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			out[key] = p.blockScalar(rest, indent)
+		default:
+			if out[key], err = scalar(rest, l.num); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// nested parses the node indented deeper than parent, or returns nil if there is none.
+func (p *yamlParser) nested(parent int) (interface{}, error) {
+	p.skipBlank()
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent() <= parent {
+		return nil, nil
+	}
+	return p.node(p.lines[p.pos].indent())
+}
+
+// blockScalar reads a literal (|) or folded (>) scalar with optional chomping indicator.
+func (p *yamlParser) blockScalar(header string, parent int) string {
+	folded := header[0] == '>'
+	chomp := byte(0)
+	if strings.Contains(header, "-") {
+		chomp = '-'
+	} else if strings.Contains(header, "+") {
+		chomp = '+'
+	}
+	var lines []string
+	blockIndent := -1
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if strings.TrimSpace(l.raw) == "" {
+			lines = append(lines, "")
+			p.pos++
+			continue
+		}
+		if l.indent() <= parent {
+			break
+		}
+		if blockIndent < 0 {
+			blockIndent = l.indent()
+		}
+		if l.indent() < blockIndent {
+			break
+		}
+		lines = append(lines, l.raw[blockIndent:])
+		p.pos++
+	}
+	trailing := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+	var s string
+	if folded {
+		var b strings.Builder
+		for i, ln := range lines {
+			// Empty lines are kept as newlines and absorb the break before them; breaks next to
+			// more-indented lines are kept; other breaks fold to a space.
+			switch {
+			case i == 0:
+			case ln == "" || strings.HasPrefix(ln, " ") || strings.HasPrefix(lines[i-1], " "):
+				b.WriteByte('\n')
+			case lines[i-1] == "":
+			default:
+				b.WriteByte(' ')
+			}
+			b.WriteString(ln)
+		}
+		s = b.String()
+	} else {
+		s = strings.Join(lines, "\n")
+	}
+	switch {
+	case len(lines) == 0 || chomp == '-':
+	case chomp == '+':
+		s += "\n" + strings.Repeat("\n", trailing)
+	default:
+		s += "\n"
+	}
+	return s
+}
+
+// keySplit returns the index of the colon ending a mapping key in t, or -1.
+func keySplit(t string) int {
+	var quote byte
+	for i := 0; i < len(t); i++ {
+		c := t[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && i == 0:
+			quote = c
+		case c == '[' || c == '{':
+			if i == 0 {
+				return -1
+			}
+		case c == ':' && (i+1 == len(t) || t[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes a trailing "# comment" that is not inside quotes.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '[' || s[i-1] == ',' || s[i-1] == ':' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' '):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func scalarString(t string, line int) (string, error) {
+	v, err := scalar(t, line)
+	if err != nil {
+		return "", err
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return t, nil
+}
+
+// yamlNumber matches the YAML 1.2 core schema's decimal integers and floats.
+var yamlNumber = regexp.MustCompile(`^[-+]?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?)(?:[eE][-+]?[0-9]+)?$`)
+
+// scalar interprets an inline value: quoted string, flow collection, or plain scalar.
+func scalar(t string, line int) (interface{}, error) {
+	switch {
+	case t == "":
+		return nil, nil
+	case t[0] == '"':
+		s, err := strconv.Unquote(t)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad double-quoted string %s", line, t)
+		}
+		return s, nil
+	case t[0] == '\'':
+		if len(t) < 2 || t[len(t)-1] != '\'' {
+			return nil, fmt.Errorf("line %d: unterminated single-quoted string", line)
+		}
+		return strings.ReplaceAll(t[1:len(t)-1], "''", "'"), nil
+	case t[0] == '[' || t[0] == '{':
+		return flow(t, line)
+	}
+	switch t {
+	case "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	switch strings.TrimLeft(t, "+-") {
+	case ".inf", ".Inf", ".INF", ".nan", ".NaN", ".NAN":
+		return nil, fmt.Errorf("line %d: %s has no JSON representation", line, t)
+	}
+	if yamlNumber.MatchString(t) {
+		if f, err := strconv.ParseFloat(t, 64); err == nil {
+			return f, nil
+		}
+	}
+	return t, nil
+}
+
+// flow parses a single-line flow sequence or mapping of scalars (nesting allowed).
+func flow(t string, line int) (interface{}, error) {
+	var v interface{}
+	if json.Unmarshal([]byte(t), &v) == nil {
+		return v, nil
+	}
+	open, close := t[0], byte(']')
+	if open == '{' {
+		close = '}'
+	}
+	if t[len(t)-1] != close {
+		return nil, fmt.Errorf("line %d: unterminated flow collection", line)
+	}
+	items, err := splitFlow(t[1 : len(t)-1])
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v", line, err)
+	}
+	if open == '[' {
+		out := make([]interface{}, 0, len(items))
+		for _, it := range items {
+			v, err := scalar(it, line)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	}
+	out := make(map[string]interface{}, len(items))
+	for _, it := range items {
+		i := keySplit(it)
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: expected key: value in %q", line, it)
+		}
+		k, err := scalarString(strings.TrimSpace(it[:i]), line)
+		if err != nil {
+			return nil, err
+		}
+		if out[k], err = scalar(strings.TrimSpace(it[i+1:]), line); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// splitFlow splits flow collection items on top-level commas.
+func splitFlow(s string) ([]string, error) {
+	var items []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if quote != 0 || depth != 0 {
+		return nil, fmt.Errorf("unbalanced flow collection")
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items, nil
+}
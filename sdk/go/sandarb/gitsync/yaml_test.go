@@ -0,0 +1,112 @@
+package gitsync
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"scalars", "s: hello world\ni: 42\nf: 1.5\nb: true\nn: ~\nhex: 0x1F\n",
+			`{"b":true,"f":1.5,"hex":"0x1F","i":42,"n":null,"s":"hello world"}`},
+		{"quoted", "a: \"tab\\tand # not a comment\"\nb: 'it''s'\nc: plain # comment\n",
+			`{"a":"tab\tand # not a comment","b":"it's","c":"plain"}`},
+		{"nested mapping", "outer:\n  inner:\n    leaf: 1\n  other: x\n",
+			`{"outer":{"inner":{"leaf":1},"other":"x"}}`},
+		{"sequence under key", "tags:\n  - a\n  - b\n",
+			`{"tags":["a","b"]}`},
+		{"sequence at key indent", "tags:\n- a\n- b\nnext: 1\n",
+			`{"next":1,"tags":["a","b"]}`},
+		{"sequence of mappings", "rules:\n  - name: r1\n    limit: 5\n  - name: r2\n",
+			`{"rules":[{"limit":5,"name":"r1"},{"name":"r2"}]}`},
+		{"flow collections", "list: [a, 'b c', 3]\nmap: {k: v, n: [1, 2]}\njson: {\"x\": [true]}\n",
+			`{"json":{"x":[true]},"list":["a","b c",3],"map":{"k":"v","n":[1,2]}}`},
+		{"literal block", "text: |\n  line one\n    indented\n\n  line three\nafter: 1\n",
+			`{"after":1,"text":"line one\n  indented\n\nline three\n"}`},
+		{"folded block strip", "text: >-\n  one\n  two\n\n  three\n",
+			`{"text":"one two\nthree"}`},
+		{"literal keep", "text: |+\n  keep\n\n",
+			`{"text":"keep\n\n"}`},
+		{"document markers and BOM", "\uFEFF%YAML 1.2\n--- # start\na: 1\n...\n# trailing comment\n",
+			`{"a":1}`},
+		{"markers inside block scalar", "content: |\n  intro\n  ---\n  body\n  ...\n  tail\nname: x\n",
+			`{"content":"intro\n---\nbody\n...\ntail\n","name":"x"}`},
+		{"numbers", "a: -7\nb: +1.5e3\nc: .5\nd: 007\ne: nan\nf: inf\ng: infinity\nh: 1_000\ni: 1e\n",
+			`{"a":-7,"b":1500,"c":0.5,"d":7,"e":"nan","f":"inf","g":"infinity","h":"1_000","i":"1e"}`},
+		{"block scalar sequence items", "steps:\n  - |\n    first\n    line\n  - >-\n    folded\n    text\n  - plain\n",
+			`{"steps":["first\nline\n","folded text","plain"]}`},
+		{"empty", "# only a comment\n", `null`},
+	}
+	for _, tt := range tests {
+		v, err := decodeYAML([]byte(tt.in))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		got, _ := json.Marshal(v)
+		if string(got) != tt.want {
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"tab indentation", "a:\n\tb: 1\n", "line 2: tabs"},
+		{"duplicate key", "a: 1\na: 2\n", "line 2: duplicate key"},
+		{"bad indentation", "a:\n    b: 1\n  c: 2\n", "line 3"},
+		{"unterminated quote", "a: 'open\n", "line 1: unterminated"},
+		{"unbalanced flow", "a: [1, [2]\n", "line 1"},
+		{"trailing content", "- a\nb: 1\n", "line 2"},
+		{"second document", "a: 1\n---\nb: 2\n", "line 2: multiple documents"},
+		{"content after end marker", "---\na: 1\n...\nb: 2\n", "line 4: multiple documents"},
+		{"infinity", "a: -.inf\n", "line 1: -.inf has no JSON representation"},
+		{"nan in flow", "a: [1, .NaN]\n", "line 1: .NaN has no JSON"},
+	}
+	for _, tt := range tests {
+		_, err := decodeYAML([]byte(tt.in))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want one containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, data string) {
+		t.Helper()
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("contexts/kyc-policy.yaml", "description: KYC rules\ntags: [kyc]\ncontent:\n  max_age_days: 30\n")
+	write("prompts/support.yml", "content: |\n  You are a support agent.\nmodel: gpt-4o\n")
+	write("prompts/README.md", "ignored")
+
+	defs, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs.Contexts) != 1 || defs.Contexts[0].Name != "kyc-policy" || defs.Contexts[0].Content["max_age_days"] != float64(30) {
+		t.Errorf("Contexts = %+v", defs.Contexts)
+	}
+	if len(defs.Prompts) != 1 || defs.Prompts[0].Content != "You are a support agent.\n" || *defs.Prompts[0].Model != "gpt-4o" {
+		t.Errorf("Prompts = %+v", defs.Prompts)
+	}
+
+	write("prompts/typo.yaml", "content: hi\nmodle: gpt-4o\n")
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "typo.yaml") {
+		t.Errorf("unknown field: error = %v", err)
+	}
+}
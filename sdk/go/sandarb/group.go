@@ -0,0 +1,143 @@
+package sandarb
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// GroupFailure is one failed call in a Group.
+type GroupFailure struct {
+	Op   string // "GetContext", "GetPrompt", or "Go"
+	Name string // context or prompt name; empty for Go
+	Err  error
+}
+
+// GroupError aggregates every failure from a Group. errors.Is and errors.As see each
+// underlying error.
+type GroupError struct {
+	Failures []GroupFailure
+}
+
+func (e *GroupError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		if f.Name != "" {
+			parts = append(parts, fmt.Sprintf("%s %q: %v", f.Op, f.Name, f.Err))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s: %v", f.Op, f.Err))
+		}
+	}
+	return fmt.Sprintf("sandarb: %d of the group's calls failed: %s", len(e.Failures), strings.Join(parts, "; "))
+}
+
+// Unwrap returns the underlying errors.
+func (e *GroupError) Unwrap() []error {
+	out := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		out[i] = f.Err
+	}
+	return out
+}
+
+// Group runs governed calls concurrently under one agent and trace, at most limit at a time.
+// Unlike errgroup, a failure doesn't cancel the other calls; Wait reports all of them.
+//
+//	g := client.NewGroup(agentID, 4)
+//	var kyc, limits *sandarb.GetContextResult
+//	var sys *sandarb.GetPromptResult
+//	g.GetContext("kyc-policy", &kyc)
+//	g.GetContext("trading-limits", &limits)
+//	g.GetPrompt("kyc-playbook", vars, &sys)
+//	if err := g.Wait(); err != nil { ... }
+type Group struct {
+	session *Session
+	opts    []RequestOption
+	sem     chan struct{}
+	wg      sync.WaitGroup
+
+	mu       sync.Mutex
+	failures []GroupFailure
+}
+
+// NewGroup starts a Group for agentID with a fresh trace ID (or the one given via WithTraceID).
+// limit <= 0 means unbounded. opts apply to every call in the group.
+func (c *Client) NewGroup(agentID string, limit int, opts ...RequestOption) *Group {
+	return c.NewSessionWithTrace(agentID, traceIDOr(opts, newTraceID)).Group(limit, opts...)
+}
+
+// Group starts a Group whose calls use the session's agent and trace.
+func (s *Session) Group(limit int, opts ...RequestOption) *Group {
+	g := &Group{session: s, opts: opts}
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+	return g
+}
+
+// TraceID returns the trace ID shared by the group's calls.
+func (g *Group) TraceID() string { return g.session.traceID }
+
+// Session returns the session the group's calls run under.
+func (g *Group) Session() *Session { return g.session }
+
+// Go runs fn in the group. Its error, if any, is reported by Wait.
+func (g *Group) Go(fn func() error) {
+	g.run("Go", "", fn)
+}
+
+// GetContext fetches ctxName in the group and stores the result in *dst when it succeeds.
+func (g *Group) GetContext(ctxName string, dst **GetContextResult, opts ...RequestOption) {
+	g.run("GetContext", ctxName, func() error {
+		r, err := g.session.GetContext(ctxName, g.callOpts(opts)...)
+		if err == nil {
+			*dst = r
+		}
+		return err
+	})
+}
+
+// GetPrompt fetches promptName in the group and stores the result in *dst when it succeeds.
+func (g *Group) GetPrompt(promptName string, variables map[string]interface{}, dst **GetPromptResult, opts ...RequestOption) {
+	g.run("GetPrompt", promptName, func() error {
+		r, err := g.session.GetPrompt(promptName, variables, g.callOpts(opts)...)
+		if err == nil {
+			*dst = r
+		}
+		return err
+	})
+}
+
+// Wait blocks until every call has finished and returns a *GroupError if any failed.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.failures) == 0 {
+		return nil
+	}
+	return &GroupError{Failures: append([]GroupFailure(nil), g.failures...)}
+}
+
+func (g *Group) run(op, name string, fn func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if g.sem != nil {
+			g.sem <- struct{}{}
+			defer func() { <-g.sem }()
+		}
+		if err := fn(); err != nil {
+			g.mu.Lock()
+			g.failures = append(g.failures, GroupFailure{Op: op, Name: name, Err: err})
+			g.mu.Unlock()
+		}
+	}()
+}
+
+func (g *Group) callOpts(opts []RequestOption) []RequestOption {
+	if len(opts) == 0 {
+		return g.opts
+	}
+	return append(append([]RequestOption(nil), g.opts...), opts...)
+}
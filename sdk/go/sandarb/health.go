@@ -0,0 +1,129 @@
+package sandarb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ComponentStatus is the health of one server component (e.g. "database").
+type ComponentStatus struct {
+	Status string                 `json:"status"`
+	Error  string                 `json:"error,omitempty"`
+	Detail map[string]interface{} `json:"-"`
+}
+
+// HealthStatus is the server's health report from GET /api/health.
+type HealthStatus struct {
+	// Status is "healthy" or "unhealthy".
+	Status     string
+	Version    string
+	Region     string
+	Timestamp  string
+	Components map[string]ComponentStatus
+	// Latency is the round-trip time of the health request.
+	Latency  time.Duration
+	ServedBy string
+}
+
+// Healthy reports whether the server and all reported components are healthy.
+func (h *HealthStatus) Healthy() bool {
+	if h.Status != "healthy" {
+		return false
+	}
+	for _, c := range h.Components {
+		if c.Status != "connected" && c.Status != "healthy" && c.Status != "ok" {
+			return false
+		}
+	}
+	return true
+}
+
+// Ping checks that the server is reachable and healthy, returning the round-trip latency.
+func (c *Client) Ping(opts ...RequestOption) (time.Duration, error) {
+	h, err := c.Health(opts...)
+	if err != nil {
+		return 0, err
+	}
+	if !h.Healthy() {
+		return h.Latency, &SandarbError{Message: "server unhealthy", StatusCode: http.StatusServiceUnavailable}
+	}
+	return h.Latency, nil
+}
+
+// Health returns the server's version, region, and component statuses. A server reporting
+// itself unhealthy (503) yields a HealthStatus with Status "unhealthy" and a nil error.
+func (c *Client) Health(opts ...RequestOption) (*HealthStatus, error) {
+	start := time.Now()
+	// A 503 carries the unhealthy report; it is data, not an endpoint failure to fail over from.
+	r := &apiRequest{op: "Health", method: http.MethodGet, path: "/api/health", opts: opts, acceptStatus: http.StatusServiceUnavailable}
+	resp, servedBy, err := c.send(r)
+	latency := time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	region := resp.Header.Get(regionHeader)
+	var raw map[string]interface{}
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		var body struct {
+			Detail map[string]interface{} `json:"detail"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) != nil || body.Detail == nil {
+			return &HealthStatus{Status: "unhealthy", Latency: latency, ServedBy: servedBy, Region: region}, nil
+		}
+		raw = body.Detail
+	} else if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		err = fmt.Errorf("sandarb: decode health response: %w", err)
+		c.reportFailure(r, err, servedBy, time.Since(start))
+		return nil, err
+	}
+	h := &HealthStatus{Latency: latency, ServedBy: servedBy, Region: region, Components: make(map[string]ComponentStatus)}
+	for k, v := range raw {
+		switch k {
+		case "status":
+			h.Status, _ = v.(string)
+		case "version":
+			h.Version, _ = v.(string)
+		case "region":
+			h.Region, _ = v.(string)
+		case "timestamp":
+			h.Timestamp, _ = v.(string)
+		case "error":
+			h.Components["server"] = ComponentStatus{Status: "unhealthy", Error: toString(v)}
+		default:
+			if m, ok := v.(map[string]interface{}); ok {
+				if _, hasStatus := m["status"]; hasStatus {
+					h.Components[k] = ComponentStatus{Status: toString(m["status"]), Error: toString(m["error"]), Detail: m}
+				}
+			}
+		}
+	}
+	if comps, ok := raw["components"].(map[string]interface{}); ok {
+		for name, v := range comps {
+			if m, ok := v.(map[string]interface{}); ok {
+				h.Components[name] = ComponentStatus{Status: toString(m["status"]), Error: toString(m["error"]), Detail: m}
+			}
+		}
+	}
+	return h, nil
+}
+
+// ReadinessHandler returns an http.Handler for Kubernetes readiness probes: 200 when Ping
+// succeeds, 503 otherwise.
+func (c *Client) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := c.Ping(WithContext(r.Context())); err != nil {
+			http.Error(w, "sandarb unreachable: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+}
+
+func toString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
@@ -0,0 +1,273 @@
+package sandarb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const defaultImportBatchSize = 500
+
+// ActivityRecord is one activity record as sent by LogActivity and ImportActivities.
+type ActivityRecord struct {
+	AgentID   string                 `json:"agent_id"`
+	TraceID   string                 `json:"trace_id"`
+	Inputs    map[string]interface{} `json:"inputs"`
+	Outputs   map[string]interface{} `json:"outputs"`
+	Timestamp *time.Time             `json:"timestamp,omitempty"`
+	// Metadata holds SDK- and session-level annotations stored alongside inputs/outputs.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// ActivitySource yields records for ImportActivities. Next returns io.EOF when exhausted, and a
+// *MalformedRecordError for a record it skipped because it could not be decoded.
+type ActivitySource interface {
+	Next() (*ActivityRecord, error)
+}
+
+// MalformedRecordError reports a source record that could not be decoded. The source has moved
+// past it, so ImportActivities records it as a per-record error and continues.
+type MalformedRecordError struct {
+	// Line is the record's 1-based line number in the source.
+	Line int
+	Err  error
+}
+
+func (e *MalformedRecordError) Error() string {
+	return fmt.Sprintf("sandarb: ndjson line %d: %v", e.Line, e.Err)
+}
+
+func (e *MalformedRecordError) Unwrap() error { return e.Err }
+
+// NDJSONActivitySource reads ActivityRecords from newline-delimited JSON.
+type NDJSONActivitySource struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+// NewNDJSONActivitySource creates an ActivitySource over r (one JSON record per line; blank lines skipped).
+func NewNDJSONActivitySource(r io.Reader) *NDJSONActivitySource {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &NDJSONActivitySource{sc: sc}
+}
+
+// Next implements ActivitySource. A line that is not a valid record is skipped and reported as a
+// *MalformedRecordError; the next call continues with the following line.
+func (s *NDJSONActivitySource) Next() (*ActivityRecord, error) {
+	for s.sc.Scan() {
+		s.line++
+		b := bytes.TrimSpace(s.sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		var rec ActivityRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return nil, &MalformedRecordError{Line: s.line, Err: err}
+		}
+		return &rec, nil
+	}
+	if err := s.sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// SliceActivitySource is an ActivitySource over an in-memory slice.
+type SliceActivitySource struct {
+	Records []ActivityRecord
+	pos     int
+}
+
+// Next implements ActivitySource.
+func (s *SliceActivitySource) Next() (*ActivityRecord, error) {
+	if s.pos >= len(s.Records) {
+		return nil, io.EOF
+	}
+	s.pos++
+	return &s.Records[s.pos-1], nil
+}
+
+// ImportOptions configures ImportActivities.
+type ImportOptions struct {
+	// ImportID identifies the import on the server; reuse it with ResumeFrom to resume. Generated if empty.
+	ImportID string
+	// ResumeFrom skips the first N records of the source (use ImportResult.Checkpoint from a failed run).
+	ResumeFrom int64
+	// BatchSize is the number of records per NDJSON request (default 500).
+	BatchSize int
+	// OnProgress is called after each batch is acknowledged.
+	OnProgress func(ImportProgress)
+}
+
+// ImportProgress reports cumulative progress of an import.
+type ImportProgress struct {
+	ImportID string
+	Sent     int64
+	Accepted int64
+	Failed   int64
+	Batches  int
+}
+
+// RecordError is a per-record rejection, by the server or (for malformed records) the source;
+// Index is the record's position in the source, counting malformed records.
+type RecordError struct {
+	Index   int64  `json:"index"`
+	Message string `json:"error"`
+}
+
+// ImportResult summarizes an import. Checkpoint is the source index of the first record not yet
+// acknowledged; pass it as ImportOptions.ResumeFrom (with the same ImportID) to resume.
+type ImportResult struct {
+	ImportID   string
+	Accepted   int64
+	Failed     int64
+	Errors     []RecordError
+	Checkpoint int64
+}
+
+// ImportActivities streams historical activity records to the bulk endpoint in NDJSON batches.
+// On error the partial result is returned alongside it so the upload can be resumed from Checkpoint.
+func (c *Client) ImportActivities(src ActivitySource, opts ImportOptions, reqOpts ...RequestOption) (*ImportResult, error) {
+	if opts.ImportID == "" {
+		opts.ImportID = newTraceID()
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+	res := &ImportResult{ImportID: opts.ImportID}
+	var index int64
+	for ; index < opts.ResumeFrom; index++ {
+		if _, err := src.Next(); err != nil && !isMalformed(err) {
+			res.Checkpoint = index
+			if err == io.EOF {
+				return res, nil
+			}
+			return res, err
+		}
+	}
+	res.Checkpoint = index
+	progress := ImportProgress{ImportID: opts.ImportID}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for {
+		buf.Reset()
+		var indexes []int64 // source index of each record in the batch
+		var malformed []RecordError
+		consumed := int64(0)
+		var srcErr error
+		for len(indexes) < opts.BatchSize {
+			rec, err := src.Next()
+			var me *MalformedRecordError
+			if errors.As(err, &me) {
+				malformed = append(malformed, RecordError{Index: res.Checkpoint + consumed, Message: me.Error()})
+				consumed++
+				continue
+			}
+			if err != nil {
+				srcErr = err
+				break
+			}
+			if rec.Inputs == nil {
+				rec.Inputs = make(map[string]interface{})
+			}
+			if rec.Outputs == nil {
+				rec.Outputs = make(map[string]interface{})
+			}
+			if err := enc.Encode(rec); err != nil {
+				return res, fmt.Errorf("sandarb: encode record %d: %w", res.Checkpoint+consumed, err)
+			}
+			indexes = append(indexes, res.Checkpoint+consumed)
+			consumed++
+		}
+		if srcErr != nil && srcErr != io.EOF {
+			return res, srcErr
+		}
+		if len(indexes) > 0 {
+			batch, err := c.importBatch(opts.ImportID, res.Checkpoint, indexes, buf.Bytes(), reqOpts)
+			if err != nil {
+				return res, err
+			}
+			res.Accepted += batch.Accepted
+			res.Failed += int64(len(batch.Errors))
+			res.Errors = append(res.Errors, batch.Errors...)
+		}
+		if consumed > 0 {
+			res.Failed += int64(len(malformed))
+			res.Errors = mergeRecordErrors(res.Errors, malformed)
+			res.Checkpoint += consumed
+			progress.Sent += consumed
+			progress.Accepted = res.Accepted
+			progress.Failed = res.Failed
+			if len(indexes) > 0 {
+				progress.Batches++
+			}
+			if opts.OnProgress != nil {
+				opts.OnProgress(progress)
+			}
+		}
+		if srcErr == io.EOF {
+			return res, nil
+		}
+	}
+}
+
+// mergeRecordErrors appends a batch's malformed-record errors to errs, keeping the batch's
+// errors in source order.
+func mergeRecordErrors(errs, malformed []RecordError) []RecordError {
+	if len(malformed) == 0 {
+		return errs
+	}
+	start := len(errs)
+	for start > 0 && errs[start-1].Index > malformed[0].Index {
+		start--
+	}
+	errs = append(errs, malformed...)
+	sort.SliceStable(errs[start:], func(i, j int) bool { return errs[start+i].Index < errs[start+j].Index })
+	return errs
+}
+
+func isMalformed(err error) bool {
+	var me *MalformedRecordError
+	return errors.As(err, &me)
+}
+
+type importBatchResult struct {
+	Accepted int64         `json:"accepted"`
+	Errors   []RecordError `json:"errors"`
+}
+
+// importBatch posts one NDJSON batch starting at source index offset; indexes holds the source
+// index of each record sent. Record error indexes returned by the server are relative to the
+// batch and are mapped back onto the source.
+func (c *Client) importBatch(importID string, offset int64, indexes []int64, ndjson []byte, opts []RequestOption) (*importBatchResult, error) {
+	path := "/api/audit/activity/bulk?import_id=" + url.QueryEscape(importID) + "&offset=" + strconv.FormatInt(offset, 10)
+	var out importBatchResult
+	_, err := c.call(&apiRequest{
+		op: "ImportActivities", feature: FeatureBulkActivity,
+		method:      http.MethodPost,
+		path:        path,
+		body:        ndjson,
+		contentType: "application/x-ndjson",
+		opts:        opts,
+	}, nil, &out)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range out.Errors {
+		if e.Index >= 0 && e.Index < int64(len(indexes)) {
+			out.Errors[i].Index = indexes[e.Index]
+		} else {
+			out.Errors[i].Index += offset
+		}
+	}
+	return &out, nil
+}
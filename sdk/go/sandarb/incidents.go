@@ -0,0 +1,225 @@
+package sandarb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bounds of the client's record of recent calls, attached to incidents by ReportIncident.
+const (
+	recentErrorLimit = 50
+	recentTraceLimit = 100
+)
+
+// RequestInfo describes a failed call, for WithErrorHandler.
+type RequestInfo struct {
+	// Method is the SDK method, e.g. "GetContext".
+	Method     string
+	HTTPMethod string
+	// Path is the API path, without the query string (which may carry prompt variables).
+	Path    string
+	AgentID string
+	TraceID string
+	// StatusCode is the HTTP status (0 when no response was received).
+	StatusCode int
+	// RequestID is the server's request ID, when it sent one.
+	RequestID string
+	ServedBy  string
+	// Duration is the time spent on the call, including failover attempts.
+	Duration time.Duration
+}
+
+// WithErrorHandler calls fn for every failed request to the server: transport errors, error
+// responses, and responses that could not be decoded. 304 Not Modified answers to conditional
+// requests are not failures. fn runs synchronously on the calling goroutine, so it should hand
+// slow work (such as ReportIncident) to another goroutine. Failures of ReportIncident itself
+// are not passed to fn.
+func WithErrorHandler(fn func(err error, req RequestInfo)) ClientOption {
+	return func(c *Client) { c.errorHandler = fn }
+}
+
+// IncidentSeverity grades an incident.
+type IncidentSeverity string
+
+const (
+	SeverityLow      IncidentSeverity = "low"
+	SeverityMedium   IncidentSeverity = "medium"
+	SeverityHigh     IncidentSeverity = "high"
+	SeverityCritical IncidentSeverity = "critical"
+)
+
+// IncidentError is a failed call attached to an incident.
+type IncidentError struct {
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path,omitempty"`
+	AgentID    string    `json:"agent_id,omitempty"`
+	TraceID    string    `json:"trace_id,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Message    string    `json:"message"`
+}
+
+// Incident is a governance incident to record with ReportIncident.
+type Incident struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description,omitempty"`
+	Severity    IncidentSeverity `json:"severity"`
+	// AgentID defaults to the client's AgentID. When set, only that agent's recent calls are
+	// attached.
+	AgentID string `json:"agent_id,omitempty"`
+	// TraceIDs defaults to the trace IDs of the client's most recent calls.
+	TraceIDs []string `json:"trace_ids"`
+	// Errors defaults to the client's most recent failed calls.
+	Errors   []IncidentError        `json:"errors"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// IncidentRecord is an incident as recorded by the server.
+type IncidentRecord struct {
+	ID        string           `json:"id"`
+	Title     string           `json:"title"`
+	Severity  IncidentSeverity `json:"severity"`
+	Status    string           `json:"status"`
+	AgentID   string           `json:"agent_id,omitempty"`
+	CreatedAt string           `json:"created_at,omitempty"`
+}
+
+// ReportIncident records a governance incident in Sandarb's audit trail, attaching the trace IDs
+// and errors of the client's recent calls unless inc sets them, so an outage or policy failure
+// seen by an agent can be traced to the exact requests involved:
+//
+//	var client *sandarb.Client
+//	client = sandarb.NewClient(sandarb.WithErrorHandler(func(err error, req sandarb.RequestInfo) {
+//		if req.StatusCode == http.StatusForbidden {
+//			go client.ReportIncident(sandarb.Incident{Title: "access denied: " + req.Method, Severity: sandarb.SeverityHigh})
+//		}
+//	}))
+func (c *Client) ReportIncident(inc Incident, opts ...RequestOption) (*IncidentRecord, error) {
+	if inc.Title == "" {
+		return nil, fmt.Errorf("sandarb: title is required for ReportIncident")
+	}
+	if inc.Severity == "" {
+		inc.Severity = SeverityMedium
+	}
+	if inc.AgentID == "" {
+		inc.AgentID = c.AgentID
+	}
+	traces, errs := c.incidents.recent(inc.AgentID)
+	if inc.TraceIDs == nil {
+		inc.TraceIDs = traces
+	}
+	if inc.Errors == nil {
+		inc.Errors = errs
+	}
+	var out IncidentRecord
+	r := &apiRequest{op: "ReportIncident", feature: FeatureIncidents, method: http.MethodPost, path: "/api/incidents", agentID: inc.AgentID, opts: opts}
+	if _, err := c.call(r, inc, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RecentErrors returns the client's most recent failed calls, oldest first.
+func (c *Client) RecentErrors() []IncidentError {
+	_, errs := c.incidents.recent("")
+	return errs
+}
+
+// incidentLog keeps the trace IDs and failures of recent calls.
+type incidentLog struct {
+	mu     sync.Mutex
+	traces []recentTrace
+	errs   []IncidentError
+}
+
+type recentTrace struct {
+	agentID, traceID string
+}
+
+func (l *incidentLog) trace(agentID, traceID string) {
+	if traceID == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n := len(l.traces); n > 0 && l.traces[n-1].traceID == traceID {
+		return
+	}
+	if len(l.traces) == recentTraceLimit {
+		l.traces = append(l.traces[:0], l.traces[1:]...)
+	}
+	l.traces = append(l.traces, recentTrace{agentID, traceID})
+}
+
+func (l *incidentLog) failure(e IncidentError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.errs) == recentErrorLimit {
+		l.errs = append(l.errs[:0], l.errs[1:]...)
+	}
+	l.errs = append(l.errs, e)
+}
+
+// recent returns unique recent trace IDs and errors, limited to agentID's calls (and calls made
+// without an agent) when agentID is set.
+func (l *incidentLog) recent(agentID string) ([]string, []IncidentError) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	traces := []string{}
+	seen := make(map[string]bool)
+	for _, t := range l.traces {
+		if (agentID == "" || t.agentID == "" || t.agentID == agentID) && !seen[t.traceID] {
+			seen[t.traceID] = true
+			traces = append(traces, t.traceID)
+		}
+	}
+	errs := []IncidentError{}
+	for _, e := range l.errs {
+		if agentID == "" || e.AgentID == "" || e.AgentID == agentID {
+			errs = append(errs, e)
+		}
+	}
+	return traces, errs
+}
+
+// reportFailure records a failed call and passes it to the error handler.
+func (c *Client) reportFailure(r *apiRequest, err error, servedBy string, d time.Duration) {
+	var se *SandarbError
+	if errors.As(err, &se) && (se.StatusCode == http.StatusNotModified || (se.StatusCode == http.StatusNotFound && newRequestOptions(r.opts).notFoundOK)) {
+		return
+	}
+	info := RequestInfo{
+		Method:     r.op,
+		HTTPMethod: r.method,
+		Path:       strings.SplitN(r.path, "?", 2)[0],
+		AgentID:    r.agentID,
+		TraceID:    r.traceID,
+		RequestID:  r.meta.RequestID,
+		ServedBy:   servedBy,
+		Duration:   d,
+	}
+	if se != nil {
+		info.StatusCode = se.StatusCode
+		if se.Meta.RequestID != "" {
+			info.RequestID = se.Meta.RequestID
+		}
+	}
+	c.incidents.failure(IncidentError{
+		Time:       time.Now().UTC(),
+		Method:     info.Method,
+		Path:       info.Path,
+		AgentID:    info.AgentID,
+		TraceID:    info.TraceID,
+		StatusCode: info.StatusCode,
+		RequestID:  info.RequestID,
+		Message:    err.Error(),
+	})
+	if c.errorHandler != nil && r.op != "ReportIncident" {
+		c.errorHandler(err, info)
+	}
+}
@@ -0,0 +1,46 @@
+package sandarb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestErrorHandlerSeesUndecodableResponses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/inject":
+			w.Write([]byte(`<html>maintenance</html>`))
+		case r.URL.Query().Get("name") == "refused":
+			w.Write([]byte(`{"success":false}`))
+		default:
+			w.Write([]byte(`{"success":true,"data":`))
+		}
+	}))
+	defer srv.Close()
+
+	var seen []RequestInfo
+	c := NewClient(WithBaseURL(srv.URL), WithAgentID("agent"), WithoutVariableValidation(),
+		WithErrorHandler(func(err error, req RequestInfo) { seen = append(seen, req) }))
+
+	if _, err := c.GetContext("limits", "agent"); err == nil {
+		t.Error("GetContext with a malformed body succeeded")
+	}
+	if _, err := c.GetPrompt("greet", nil, "", ""); err == nil {
+		t.Error("GetPrompt with a truncated body succeeded")
+	}
+	if _, err := c.GetPrompt("refused", nil, "", ""); err == nil || !strings.Contains(err.Error(), "invalid get_prompt response") {
+		t.Errorf("GetPrompt with success false: error = %v", err)
+	}
+
+	want := []string{"GetContext /api/inject", "GetPrompt /api/prompts/pull", "GetPrompt /api/prompts/pull"}
+	if len(seen) != len(want) {
+		t.Fatalf("handler saw %d failures (%+v), want %d", len(seen), seen, len(want))
+	}
+	for i, req := range seen {
+		if got := req.Method + " " + req.Path; got != want[i] || req.TraceID == "" {
+			t.Errorf("failure %d = %+v, want %s with a trace ID", i, req, want[i])
+		}
+	}
+}
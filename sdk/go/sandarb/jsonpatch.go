@@ -0,0 +1,243 @@
+package sandarb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// patchOp is one RFC 6902 JSON Patch operation.
+type patchOp struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	From string `json:"from,omitempty"`
+	// Value is kept raw so an absent value (nil) can be told apart from an explicit null.
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// value decodes op.Value, which add, replace and test require.
+func (op patchOp) value() (interface{}, error) {
+	if op.Value == nil {
+		return nil, fmt.Errorf("missing value")
+	}
+	var v interface{}
+	if err := json.Unmarshal(op.Value, &v); err != nil {
+		return nil, fmt.Errorf("invalid value: %v", err)
+	}
+	return v, nil
+}
+
+// applyJSONPatch applies ops to doc (decoded JSON), which it may modify in place, and returns the result.
+func applyJSONPatch(doc interface{}, ops []patchOp) (interface{}, error) {
+	var err error
+	for i, op := range ops {
+		if doc, err = applyPatchOp(doc, op); err != nil {
+			return nil, fmt.Errorf("sandarb: json patch op %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return doc, nil
+}
+
+func applyPatchOp(doc interface{}, op patchOp) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, v)
+	case "remove":
+		doc, _, err = patchRemove(doc, path)
+		return doc, err
+	case "replace":
+		v, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pointerGet(doc, path); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return v, nil
+		}
+		return patchAt(doc, path, func(parent interface{}, key string) (interface{}, error) {
+			return setChild(parent, key, v)
+		})
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		var v interface{}
+		if op.Op == "move" {
+			doc, v, err = patchRemove(doc, from)
+		} else {
+			v, err = pointerGet(doc, from)
+			v = cloneValue(v)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, v)
+	case "test":
+		want, err := op.value()
+		if err != nil {
+			return nil, err
+		}
+		v, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(v, want) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+func patchAdd(doc interface{}, path []string, v interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return v, nil
+	}
+	return patchAt(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[key] = v
+			return p, nil
+		case []interface{}:
+			i := len(p)
+			if key != "-" {
+				var err error
+				if i, err = arrayIndex(key, len(p)+1); err != nil {
+					return nil, err
+				}
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = v
+			return p, nil
+		}
+		return nil, fmt.Errorf("cannot add to %s", jsonType(parent))
+	})
+}
+
+func patchRemove(doc interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the document root")
+	}
+	var removed interface{}
+	doc, err := patchAt(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			v, ok := p[key]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", key)
+			}
+			removed = v
+			delete(p, key)
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(p))
+			if err != nil {
+				return nil, err
+			}
+			removed = p[i]
+			return append(p[:i], p[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove from %s", jsonType(parent))
+	})
+	return doc, removed, err
+}
+
+// patchAt applies fn to the parent of the location path and returns node with the (possibly
+// reallocated) parent written back.
+func patchAt(node interface{}, path []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+	child, err := pointerGet(node, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	nc, err := patchAt(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	return setChild(node, path[0], nc)
+}
+
+func setChild(parent interface{}, key string, v interface{}) (interface{}, error) {
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := p[key]; !ok {
+			return nil, fmt.Errorf("member %q not found", key)
+		}
+		p[key] = v
+		return p, nil
+	case []interface{}:
+		i, err := arrayIndex(key, len(p))
+		if err != nil {
+			return nil, err
+		}
+		p[i] = v
+		return p, nil
+	}
+	return nil, fmt.Errorf("cannot index into %s", jsonType(parent))
+}
+
+func pointerGet(node interface{}, path []string) (interface{}, error) {
+	for _, key := range path {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			v, ok := n[key]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", key)
+			}
+			node = v
+		case []interface{}:
+			i, err := arrayIndex(key, len(n))
+			if err != nil {
+				return nil, err
+			}
+			node = n[i]
+		default:
+			return nil, fmt.Errorf("cannot index into %s", jsonType(node))
+		}
+	}
+	return node, nil
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped reference tokens.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array index token, which must be < n. RFC 6901 allows only "0" or
+// digits without a leading zero, so signs ("+1", "-0") are rejected.
+func arrayIndex(key string, n int) (int, error) {
+	valid := key != "" && (key == "0" || key[0] != '0')
+	for i := 0; valid && i < len(key); i++ {
+		valid = key[i] >= '0' && key[i] <= '9'
+	}
+	i, err := strconv.Atoi(key)
+	if !valid || err != nil || i >= n {
+		return 0, fmt.Errorf("invalid array index %q", key)
+	}
+	return i, nil
+}
@@ -0,0 +1,66 @@
+package sandarb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// RFC 6902 appendix A, plus cases for pointer and value edge cases.
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, want string // want "" means the patch must fail
+	}{
+		{"A.1 add object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"A.2 add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"A.3 remove object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"A.4 remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"A.5 replace value", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"A.6 move value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"A.7 move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`},
+		{"A.8 test success", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`},
+		{"A.9 test error", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, ""},
+		{"A.10 add nested member", `{"foo":"bar"}`, `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, `{"child":{"grandchild":{}},"foo":"bar"}`},
+		{"A.11 ignore unrecognized elements", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, `{"baz":"qux","foo":"bar"}`},
+		{"A.12 add to nonexistent target", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, ""},
+		{"A.14 ~ escape ordering", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10}]`, `{"/":9,"~1":10}`},
+		{"A.15 compare strings and numbers", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":"10"}]`, ""},
+		{"A.16 add array value", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, `{"foo":["bar",["abc","def"]]}`},
+		{"add explicit null", `{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`},
+		{"add without value", `{}`, `[{"op":"add","path":"/a"}]`, ""},
+		{"replace without value", `{"a":1}`, `[{"op":"replace","path":"/a"}]`, ""},
+		{"test without value", `{"a":null}`, `[{"op":"test","path":"/a"}]`, ""},
+		{"replace root", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`},
+		{"copy", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"index with plus sign", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/+1"}]`, ""},
+		{"negative zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/-0"}]`, ""},
+		{"leading zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, ""},
+		{"index out of range", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/2"}]`, ""},
+		{"add at array end index", `{"a":[1,2]}`, `[{"op":"add","path":"/a/2","value":3}]`, `{"a":[1,2,3]}`},
+		{"remove root", `{}`, `[{"op":"remove","path":""}]`, ""},
+		{"unknown op", `{}`, `[{"op":"merge","path":"/a","value":1}]`, ""},
+	}
+	for _, tt := range tests {
+		var doc interface{}
+		var ops []patchOp
+		if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+			t.Fatalf("%s: doc: %v", tt.name, err)
+		}
+		if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil {
+			t.Fatalf("%s: patch: %v", tt.name, err)
+		}
+		got, err := applyJSONPatch(doc, ops)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: applied, want an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if b, _ := json.Marshal(got); string(b) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, b, tt.want)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package sandarb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SchemaViolation is one JSON Schema validation failure; Path is a JSON pointer into the document.
+type SchemaViolation struct {
+	Path    string
+	Message string
+}
+
+// SchemaValidationError is returned when content does not conform to its registered schema.
+type SchemaValidationError struct {
+	Subject    string
+	Version    int
+	Violations []SchemaViolation
+}
+
+func (e *SchemaValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Path+": "+v.Message)
+	}
+	return fmt.Sprintf("sandarb: %s does not match schema v%d: %s", e.Subject, e.Version, strings.Join(msgs, "; "))
+}
+
+// jsonSchema is a parsed JSON Schema whose keywords have been checked by compileJSONSchema.
+type jsonSchema struct {
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// schemaKeywords maps each supported keyword to the shape of its value. Keywords outside this
+// set (e.g. $ref, if/then/else, patternProperties) are rejected when the schema is compiled
+// rather than silently ignored, so a schema is never enforced more loosely than it reads.
+var schemaKeywords = map[string]string{
+	"type": "type", "enum": "array", "const": "any",
+	"required": "strings", "properties": "schemas", "additionalProperties": "schema",
+	"dependentRequired": "dependentRequired",
+	"items":             "schema", "minItems": "number", "maxItems": "number",
+	"minLength": "number", "maxLength": "number", "pattern": "pattern",
+	"minimum": "number", "maximum": "number", "exclusiveMinimum": "number", "exclusiveMaximum": "number",
+	"allOf": "schemaList", "anyOf": "schemaList", "oneOf": "schemaList", "not": "schema",
+	// Annotations, which do not affect validation.
+	"$schema": "any", "$id": "any", "$comment": "any", "title": "any", "description": "any",
+	"default": "any", "examples": "any", "deprecated": "any", "readOnly": "any", "writeOnly": "any",
+	"format": "any",
+}
+
+var schemaTypes = map[string]bool{"null": true, "boolean": true, "object": true, "array": true, "number": true, "integer": true, "string": true}
+
+// compileJSONSchema parses schema and checks it uses only the supported subset of JSON Schema:
+// type, enum, const, required, properties, additionalProperties, dependentRequired, items,
+// min/maxItems, min/maxLength, pattern, minimum/maximum, exclusiveMinimum/Maximum, allOf,
+// anyOf, oneOf, and not, plus annotations. Unsupported keywords and invalid patterns are errors,
+// which callers wrap with the schema they came from.
+func compileJSONSchema(schema json.RawMessage) (*jsonSchema, error) {
+	var root interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	js := &jsonSchema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := js.check(root, ""); err != nil {
+		return nil, err
+	}
+	return js, nil
+}
+
+func (js *jsonSchema) check(node interface{}, path string) error {
+	if _, ok := node.(bool); ok {
+		return nil
+	}
+	s, ok := node.(map[string]interface{})
+	if !ok {
+		return schemaError(path, "schema must be an object or boolean, got %s", jsonType(node))
+	}
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v, at := s[k], path+"/"+k
+		kind, ok := schemaKeywords[k]
+		if !ok {
+			return schemaError(at, "unsupported keyword %q", k)
+		}
+		var err error
+		switch kind {
+		case "type":
+			err = checkSchemaType(v, at)
+		case "array":
+			if _, ok := v.([]interface{}); !ok {
+				err = schemaError(at, "expected an array")
+			}
+		case "number":
+			if _, ok := number(v); !ok {
+				err = schemaError(at, "expected a number")
+			}
+		case "strings":
+			err = checkStrings(v, at)
+		case "pattern":
+			p, ok := v.(string)
+			if !ok {
+				return schemaError(at, "expected a string")
+			}
+			re, cerr := regexp.Compile(p)
+			if cerr != nil {
+				return schemaError(at, "invalid pattern: %v", cerr)
+			}
+			js.patterns[p] = re
+		case "schema":
+			err = js.check(v, at)
+		case "schemas":
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return schemaError(at, "expected an object")
+			}
+			for name, sub := range m {
+				if err := js.check(sub, at+"/"+name); err != nil {
+					return err
+				}
+			}
+		case "schemaList":
+			list, ok := v.([]interface{})
+			if !ok || len(list) == 0 {
+				return schemaError(at, "expected a non-empty array")
+			}
+			for i, sub := range list {
+				if err := js.check(sub, fmt.Sprintf("%s/%d", at, i)); err != nil {
+					return err
+				}
+			}
+		case "dependentRequired":
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return schemaError(at, "expected an object")
+			}
+			for name, deps := range m {
+				if err := checkStrings(deps, at+"/"+name); err != nil {
+					return err
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkSchemaType(v interface{}, at string) error {
+	names, isList := v.([]interface{})
+	if !isList {
+		names = []interface{}{v}
+	}
+	for _, n := range names {
+		if s, ok := n.(string); !ok || !schemaTypes[s] {
+			return schemaError(at, "unknown type %v", n)
+		}
+	}
+	return nil
+}
+
+func checkStrings(v interface{}, at string) error {
+	list, ok := v.([]interface{})
+	if !ok {
+		return schemaError(at, "expected an array of strings")
+	}
+	for _, x := range list {
+		if _, ok := x.(string); !ok {
+			return schemaError(at, "expected an array of strings")
+		}
+	}
+	return nil
+}
+
+func schemaError(path, format string, args ...interface{}) error {
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Errorf("invalid schema at %s: %s", path, fmt.Sprintf(format, args...))
+}
+
+// validate validates doc (decoded JSON) against the schema.
+func (js *jsonSchema) validate(doc interface{}) []SchemaViolation {
+	var out []SchemaViolation
+	js.validateNode(js.root, doc, "", &out)
+	return out
+}
+
+func (js *jsonSchema) validateNode(schema, v interface{}, path string, out *[]SchemaViolation) {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		if b, isBool := schema.(bool); isBool && !b {
+			addViolation(out, path, "value not allowed")
+		}
+		return
+	}
+	fail := func(format string, args ...interface{}) { addViolation(out, path, fmt.Sprintf(format, args...)) }
+
+	if t, ok := s["type"]; ok && !matchesType(t, v) {
+		fail("expected type %v, got %s", t, jsonType(v))
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value not in enum")
+		}
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, v) {
+		fail("value does not match const")
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if req, ok := s["required"].([]interface{}); ok {
+			for _, r := range req {
+				if name, _ := r.(string); name != "" {
+					if _, present := t[name]; !present {
+						addViolation(out, path+"/"+name, "required property missing")
+					}
+				}
+			}
+		}
+		if dr, ok := s["dependentRequired"].(map[string]interface{}); ok {
+			for prop, deps := range dr {
+				if _, present := t[prop]; !present {
+					continue
+				}
+				for _, d := range deps.([]interface{}) {
+					if name := d.(string); name != "" {
+						if _, present := t[name]; !present {
+							addViolation(out, path+"/"+name, fmt.Sprintf("required when %q is present", prop))
+						}
+					}
+				}
+			}
+		}
+		props, _ := s["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := props[k]; ok {
+				js.validateNode(ps, t[k], path+"/"+k, out)
+				continue
+			}
+			if ap, ok := s["additionalProperties"]; ok {
+				js.validateNode(ap, t[k], path+"/"+k, out)
+			}
+		}
+	case []interface{}:
+		if n, ok := number(s["minItems"]); ok && float64(len(t)) < n {
+			fail("expected at least %v items", n)
+		}
+		if n, ok := number(s["maxItems"]); ok && float64(len(t)) > n {
+			fail("expected at most %v items", n)
+		}
+		if items, ok := s["items"]; ok {
+			for i, it := range t {
+				js.validateNode(items, it, fmt.Sprintf("%s/%d", path, i), out)
+			}
+		}
+	case string:
+		n := float64(utf8.RuneCountInString(t))
+		if m, ok := number(s["minLength"]); ok && n < m {
+			fail("shorter than minLength %v", m)
+		}
+		if m, ok := number(s["maxLength"]); ok && n > m {
+			fail("longer than maxLength %v", m)
+		}
+		if p, ok := s["pattern"].(string); ok && !js.patterns[p].MatchString(t) {
+			fail("does not match pattern %q", p)
+		}
+	case float64:
+		if m, ok := number(s["minimum"]); ok && t < m {
+			fail("less than minimum %v", m)
+		}
+		if m, ok := number(s["maximum"]); ok && t > m {
+			fail("greater than maximum %v", m)
+		}
+		if m, ok := number(s["exclusiveMinimum"]); ok && t <= m {
+			fail("not greater than exclusiveMinimum %v", m)
+		}
+		if m, ok := number(s["exclusiveMaximum"]); ok && t >= m {
+			fail("not less than exclusiveMaximum %v", m)
+		}
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			js.validateNode(sub, v, path, out)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok && js.countMatches(anyOf, v, path) == 0 {
+		fail("does not match any of anyOf")
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok && js.countMatches(oneOf, v, path) != 1 {
+		fail("does not match exactly one of oneOf")
+	}
+	if not, ok := s["not"]; ok && js.countMatches([]interface{}{not}, v, path) == 1 {
+		fail("matches the schema in not")
+	}
+}
+
+func (js *jsonSchema) countMatches(schemas []interface{}, v interface{}, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		var errs []SchemaViolation
+		js.validateNode(sub, v, path, &errs)
+		if len(errs) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func addViolation(out *[]SchemaViolation, path, msg string) {
+	if path == "" {
+		path = "/"
+	}
+	*out = append(*out, SchemaViolation{Path: path, Message: msg})
+}
+
+func matchesType(t, v interface{}) bool {
+	switch tt := t.(type) {
+	case string:
+		return typeMatches(tt, v)
+	case []interface{}:
+		for _, x := range tt {
+			if s, ok := x.(string); ok && typeMatches(s, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func typeMatches(t string, v interface{}) bool {
+	switch t {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	default:
+		return jsonType(v) == t
+	}
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func number(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func jsonEqual(a, b interface{}) bool {
+	ab, err1 := json.Marshal(a)
+	bb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(ab) == string(bb)
+}
@@ -0,0 +1,98 @@
+package sandarb
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		paths  []string // paths of the expected violations; none means valid
+	}{
+		{"type ok", `{"type":"string"}`, `"x"`, nil},
+		{"type mismatch", `{"type":"string"}`, `1`, []string{"/"}},
+		{"type list", `{"type":["string","null"]}`, `null`, nil},
+		{"integer accepts whole float", `{"type":"integer"}`, `3.0`, nil},
+		{"integer rejects fraction", `{"type":"integer"}`, `3.5`, []string{"/"}},
+		{"number accepts integer", `{"type":"number"}`, `3`, nil},
+		{"number rejects numeric string", `{"type":"number"}`, `"3"`, []string{"/"}},
+		{"enum", `{"enum":["a",1,{"k":true}]}`, `{"k":true}`, nil},
+		{"enum miss", `{"enum":["a",1]}`, `"b"`, []string{"/"}},
+		{"enum number forms", `{"enum":[1]}`, `1.0`, nil},
+		{"const", `{"const":{"a":[1,2]}}`, `{"a":[1,2]}`, nil},
+		{"const miss", `{"const":"a"}`, `"b"`, []string{"/"}},
+		{"required", `{"required":["a","b"]}`, `{"a":1}`, []string{"/b"}},
+		{"properties", `{"properties":{"a":{"type":"string"}}}`, `{"a":1,"b":1}`, []string{"/a"}},
+		{"additionalProperties false", `{"properties":{"a":{}},"additionalProperties":false}`, `{"a":1,"b":2}`, []string{"/b"}},
+		{"additionalProperties schema", `{"additionalProperties":{"type":"number"}}`, `{"a":1,"b":"x"}`, []string{"/b"}},
+		{"dependentRequired", `{"dependentRequired":{"card":["cvv"]}}`, `{"card":"4111"}`, []string{"/cvv"}},
+		{"dependentRequired absent", `{"dependentRequired":{"card":["cvv"]}}`, `{}`, nil},
+		{"items", `{"items":{"type":"integer"}}`, `[1,"x",3]`, []string{"/1"}},
+		{"minItems", `{"minItems":2}`, `[1]`, []string{"/"}},
+		{"maxItems", `{"maxItems":1}`, `[1,2]`, []string{"/"}},
+		{"minLength counts runes", `{"minLength":2}`, `"é"`, []string{"/"}},
+		{"maxLength counts runes", `{"maxLength":2}`, `"éé"`, nil},
+		{"pattern", `{"pattern":"^[a-z]+$"}`, `"abc"`, nil},
+		{"pattern miss", `{"pattern":"^[a-z]+$"}`, `"ab1"`, []string{"/"}},
+		{"pattern unanchored", `{"pattern":"\\d"}`, `"a1b"`, nil},
+		{"minimum", `{"minimum":1}`, `0`, []string{"/"}},
+		{"maximum", `{"maximum":1}`, `1`, nil},
+		{"exclusiveMinimum", `{"exclusiveMinimum":1}`, `1`, []string{"/"}},
+		{"exclusiveMaximum", `{"exclusiveMaximum":1}`, `0.5`, nil},
+		{"allOf", `{"allOf":[{"minimum":1},{"maximum":2}]}`, `3`, []string{"/"}},
+		{"anyOf", `{"anyOf":[{"type":"string"},{"type":"null"}]}`, `null`, nil},
+		{"anyOf miss", `{"anyOf":[{"type":"string"},{"type":"null"}]}`, `1`, []string{"/"}},
+		{"oneOf", `{"oneOf":[{"minimum":1},{"maximum":0}]}`, `2`, nil},
+		{"oneOf both", `{"oneOf":[{"minimum":1},{"minimum":2}]}`, `3`, []string{"/"}},
+		{"not", `{"not":{"type":"null"}}`, `null`, []string{"/"}},
+		{"not ok", `{"not":{"type":"null"}}`, `1`, nil},
+		{"false schema", `{"properties":{"a":false}}`, `{"a":1}`, []string{"/a"}},
+		{"annotations ignored", `{"title":"t","description":"d","format":"email","default":1}`, `"x"`, nil},
+		{"nested path", `{"properties":{"a":{"items":{"required":["id"]}}}}`, `{"a":[{},{"id":1}]}`, []string{"/a/0/id"}},
+	}
+	for _, tt := range tests {
+		js, err := compileJSONSchema(json.RawMessage(tt.schema))
+		if err != nil {
+			t.Errorf("%s: compile: %v", tt.name, err)
+			continue
+		}
+		var doc interface{}
+		if err := json.Unmarshal([]byte(tt.doc), &doc); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, v := range js.validate(doc) {
+			got = append(got, v.Path)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.paths, ",") {
+			t.Errorf("%s: violations at %v, want %v", tt.name, got, tt.paths)
+		}
+	}
+}
+
+func TestJSONSchemaRejectsUnsupported(t *testing.T) {
+	tests := []struct {
+		name, schema, want string
+	}{
+		{"$ref", `{"properties":{"a":{"$ref":"#/$defs/a"}}}`, `/properties/a/$ref: unsupported keyword "$ref"`},
+		{"if/then", `{"if":{},"then":{}}`, `unsupported keyword "if"`},
+		{"patternProperties", `{"patternProperties":{}}`, `unsupported keyword "patternProperties"`},
+		{"unknown type", `{"type":"float"}`, "unknown type float"},
+		{"non-RE2 pattern", `{"pattern":"(?=a)"}`, "/pattern: invalid pattern"},
+		{"non-numeric bound", `{"minimum":"1"}`, "/minimum: expected a number"},
+		{"empty anyOf", `{"anyOf":[]}`, "/anyOf: expected a non-empty array"},
+		{"bad required", `{"required":[1]}`, "/required: expected an array of strings"},
+		{"schema not object", `{"items":1}`, "/items: schema must be an object or boolean"},
+		{"not JSON", `{`, "invalid schema"},
+	}
+	for _, tt := range tests {
+		_, err := compileJSONSchema(json.RawMessage(tt.schema))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want one containing %q", tt.name, err, tt.want)
+		}
+	}
+}
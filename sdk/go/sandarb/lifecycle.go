@@ -0,0 +1,180 @@
+package sandarb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// lifecycle tracks the client's long-running background goroutines (e.g. flag watchers).
+type lifecycle struct {
+	once   sync.Once
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	closed int32
+	drain  context.Context // Close's context, set before ctx is canceled
+
+	mu          sync.Mutex // guards drain and undelivered, and orders goBackground against Close
+	undelivered map[string]int
+}
+
+func (l *lifecycle) init() {
+	l.once.Do(func() { l.ctx, l.cancel = context.WithCancel(context.Background()) })
+}
+
+// goBackground runs fn in a tracked goroutine; ctx is canceled when the client shuts down.
+// It reports false, without running fn, once the client is closed.
+func (c *Client) goBackground(fn func(ctx context.Context)) bool {
+	c.life.init()
+	c.life.mu.Lock()
+	if atomic.LoadInt32(&c.life.closed) == 1 {
+		c.life.mu.Unlock()
+		return false
+	}
+	c.life.wg.Add(1)
+	c.life.mu.Unlock()
+	go func() {
+		defer c.life.wg.Done()
+		fn(c.life.ctx)
+	}()
+	return true
+}
+
+// shutdownContext bounds the final flush a background goroutine performs after its ctx is
+// canceled: Close's context during shutdown, else a short timeout.
+func (c *Client) shutdownContext() (context.Context, context.CancelFunc) {
+	c.life.mu.Lock()
+	drain := c.life.drain
+	c.life.mu.Unlock()
+	if drain != nil {
+		return context.WithCancel(drain)
+	}
+	return context.WithTimeout(context.Background(), 5*time.Second)
+}
+
+// reportUndelivered records n items of kind that a background component gave up on, for Close.
+func (c *Client) reportUndelivered(kind string, n int) {
+	if n <= 0 {
+		return
+	}
+	c.life.mu.Lock()
+	defer c.life.mu.Unlock()
+	if c.life.undelivered == nil {
+		c.life.undelivered = make(map[string]int)
+	}
+	c.life.undelivered[kind] += n
+}
+
+// ShutdownError is returned by Close when pending work could not be completed.
+type ShutdownError struct {
+	// Undelivered counts items not delivered, by kind: "activity" (records left in the spool,
+	// which remain on disk for the next OpenSpool), "policy_decisions", "sink".
+	Undelivered map[string]int
+	// Err is the first error encountered, e.g. ctx's deadline or a delivery failure.
+	Err error
+}
+
+func (e *ShutdownError) Error() string {
+	kinds := make([]string, 0, len(e.Undelivered))
+	for k, n := range e.Undelivered {
+		kinds = append(kinds, fmt.Sprintf("%s=%d", k, n))
+	}
+	sort.Strings(kinds)
+	msg := "sandarb: close: undelivered " + strings.Join(kinds, ", ")
+	if len(kinds) == 0 {
+		msg = "sandarb: close"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *ShutdownError) Unwrap() error { return e.Err }
+
+// ActivityFlusher is implemented by activity sinks that buffer records (e.g. async producers);
+// Close calls Flush before returning.
+type ActivityFlusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Close shuts the client down: it stops background watchers and refreshers, lets activity and
+// decision flushers send what they hold, replays the activity spool, and flushes the activity
+// sink, all bounded by ctx. Anything left undelivered is reported as a *ShutdownError. The spool
+// itself is not closed. Close is safe to call more than once; later calls return nil.
+func (c *Client) Close(ctx context.Context) error {
+	c.life.init()
+	// Holding mu also waits out any goBackground that saw the client open, so its Add precedes Wait.
+	c.life.mu.Lock()
+	if !atomic.CompareAndSwapInt32(&c.life.closed, 0, 1) {
+		c.life.mu.Unlock()
+		return nil
+	}
+	c.life.drain = ctx
+	c.life.mu.Unlock()
+	c.life.cancel()
+
+	var firstErr error
+	fail := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		c.life.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		fail(fmt.Errorf("waiting for background tasks: %w", ctx.Err()))
+	}
+
+	if c.spool != nil {
+		fail(c.flushSpool(ctx))
+	}
+	if f, ok := c.sink.(ActivityFlusher); ok {
+		if err := f.Flush(ctx); err != nil {
+			fail(&SinkError{Err: err})
+		}
+	}
+
+	c.life.mu.Lock()
+	undelivered := c.life.undelivered
+	c.life.mu.Unlock()
+	if c.spool != nil {
+		if n := c.spool.Len(); n > 0 {
+			if undelivered == nil {
+				undelivered = make(map[string]int)
+			}
+			undelivered["activity"] = n
+		}
+	}
+	if firstErr == nil && len(undelivered) == 0 {
+		return nil
+	}
+	return &ShutdownError{Undelivered: undelivered, Err: firstErr}
+}
+
+// flushSpool waits for any background replay to finish, then replays the spool once more under ctx.
+// The replay guard is left held so no further background replays start.
+func (c *Client) flushSpool(ctx context.Context) error {
+	t := time.NewTicker(10 * time.Millisecond)
+	defer t.Stop()
+	for !atomic.CompareAndSwapInt32(&c.replaying, 0, 1) {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for spool replay: %w", ctx.Err())
+		case <-t.C:
+		}
+	}
+	_, err := c.spool.Replay(func(rec *ActivityRecord) error { return c.postActivity(rec, []RequestOption{WithContext(ctx)}) })
+	return err
+}
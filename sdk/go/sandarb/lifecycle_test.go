@@ -0,0 +1,71 @@
+package sandarb
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCloseStopsBackgroundTasks(t *testing.T) {
+	c := NewClient(WithBaseURL("http://127.0.0.1:0"))
+	var stopped int32
+	for i := 0; i < 3; i++ {
+		if !c.goBackground(func(ctx context.Context) {
+			<-ctx.Done()
+			atomic.AddInt32(&stopped, 1)
+		}) {
+			t.Fatal("goBackground refused before Close")
+		}
+	}
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close = %v", err)
+	}
+	if n := atomic.LoadInt32(&stopped); n != 3 {
+		t.Errorf("%d of 3 background tasks stopped before Close returned", n)
+	}
+	if c.goBackground(func(context.Context) { t.Error("task started after Close") }) {
+		t.Error("goBackground after Close = true")
+	}
+	if err := c.Close(context.Background()); err != nil {
+		t.Errorf("second Close = %v, want nil", err)
+	}
+}
+
+func TestCloseReportsUndelivered(t *testing.T) {
+	type key struct{}
+	c := NewClient(WithBaseURL("http://127.0.0.1:0"))
+	c.goBackground(func(ctx context.Context) {
+		<-ctx.Done()
+		fctx, cancel := c.shutdownContext()
+		defer cancel()
+		if fctx.Value(key{}) != "close" {
+			t.Error("final flush is not bounded by Close's context")
+		}
+		c.reportUndelivered("policy_decisions", 3)
+	})
+	err := c.Close(context.WithValue(context.Background(), key{}, "close"))
+	var se *ShutdownError
+	if !errors.As(err, &se) || se.Undelivered["policy_decisions"] != 3 || se.Err != nil {
+		t.Fatalf("Close = %#v, want a *ShutdownError with 3 undelivered policy_decisions", err)
+	}
+	if got, want := err.Error(), "sandarb: close: undelivered policy_decisions=3"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestCloseDeadlineWhileTaskBlocks(t *testing.T) {
+	c := NewClient(WithBaseURL("http://127.0.0.1:0"))
+	release := make(chan struct{})
+	defer close(release)
+	c.goBackground(func(context.Context) { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.Close(ctx)
+	var se *ShutdownError
+	if !errors.As(err, &se) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close = %v, want a *ShutdownError wrapping the deadline", err)
+	}
+}
@@ -0,0 +1,62 @@
+package sandarb
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ContextLineage describes how a context version was produced: its sources, approvals, and upstream contexts.
+type ContextLineage struct {
+	ContextVersionID string               `json:"context_version_id"`
+	ContextID        string               `json:"context_id"`
+	ContextName      string               `json:"context_name"`
+	Version          int                  `json:"version"`
+	CreatedBy        string               `json:"created_by,omitempty"`
+	CreatedAt        *time.Time           `json:"created_at,omitempty"`
+	Sources          []LineageSource      `json:"sources"`
+	Approvals        []LineageApproval    `json:"approvals"`
+	Upstream         []UpstreamContextRef `json:"upstream"`
+}
+
+// LineageSource is an input (document, system, or person) that contributed to a context version.
+type LineageSource struct {
+	Type        string `json:"type"`
+	URI         string `json:"uri,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// LineageApproval is an approval decision recorded against a context version.
+type LineageApproval struct {
+	Approver  string     `json:"approver"`
+	Status    string     `json:"status"`
+	Comment   string     `json:"comment,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// UpstreamContextRef is a context version this version was derived from or composed with.
+type UpstreamContextRef struct {
+	ContextVersionID string `json:"context_version_id"`
+	ContextName      string `json:"context_name"`
+	Version          int    `json:"version"`
+	Relation         string `json:"relation,omitempty"`
+}
+
+// GetContextLineage returns the provenance of a context version (from GetContextResult.ContextVersionID).
+func (c *Client) GetContextLineage(contextVersionID string, opts ...RequestOption) (*ContextLineage, error) {
+	if contextVersionID == "" {
+		return nil, fmt.Errorf("sandarb: context_version_id is required for GetContextLineage")
+	}
+	var out ContextLineage
+	_, err := c.call(&apiRequest{
+		op: "GetContextLineage", feature: FeatureContextLineage,
+		method: http.MethodGet,
+		path:   "/api/contexts/versions/" + url.PathEscape(contextVersionID) + "/lineage",
+		opts:   opts,
+	}, nil, &out)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
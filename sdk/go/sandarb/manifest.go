@@ -0,0 +1,176 @@
+package sandarb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// manifestTTL bounds how long a fetched manifest is reused before GetPrompt refetches it.
+const manifestTTL = 5 * time.Minute
+
+// manifestFailureTTL is how long GetPrompt skips validation of a prompt after its manifest
+// could not be fetched (transport error or 5xx), instead of retrying on every call.
+const manifestFailureTTL = 30 * time.Second
+
+// VariableType is the declared type of a prompt template variable.
+type VariableType string
+
+const (
+	VarString  VariableType = "string"
+	VarNumber  VariableType = "number"
+	VarInteger VariableType = "integer"
+	VarBoolean VariableType = "boolean"
+	VarObject  VariableType = "object"
+	VarArray   VariableType = "array"
+	VarAny     VariableType = "any"
+)
+
+// VariableSpec declares one template variable of a prompt.
+type VariableSpec struct {
+	Name        string        `json:"name"`
+	Type        VariableType  `json:"type"`
+	Required    bool          `json:"required"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Description string        `json:"description,omitempty"`
+}
+
+// PromptManifest lists the variables declared by a prompt version.
+type PromptManifest struct {
+	Name      string         `json:"name"`
+	Version   int            `json:"version"`
+	Variables []VariableSpec `json:"variables"`
+}
+
+// ErrInvalidVariables is matched (via errors.Is) by *VariablesError.
+var ErrInvalidVariables = errors.New("sandarb: invalid prompt variables")
+
+// VariableProblem is one missing, mistyped, or undeclared variable.
+type VariableProblem struct {
+	Name    string
+	Message string
+}
+
+// VariablesError is returned by GetPrompt when variables don't match the prompt's manifest.
+type VariablesError struct {
+	Prompt   string
+	Problems []VariableProblem
+}
+
+func (e *VariablesError) Error() string {
+	parts := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		parts = append(parts, p.Name+": "+p.Message)
+	}
+	return fmt.Sprintf("sandarb: invalid variables for prompt %q: %s", e.Prompt, strings.Join(parts, "; "))
+}
+
+// Is makes errors.Is(err, ErrInvalidVariables) true.
+func (e *VariablesError) Is(target error) bool { return target == ErrInvalidVariables }
+
+type cachedManifest struct {
+	manifest *PromptManifest // nil when the server publishes no manifest for the prompt
+	expires  time.Time
+}
+
+// WithoutVariableValidation disables local validation of GetPrompt variables.
+func WithoutVariableValidation() ClientOption {
+	return func(c *Client) { c.skipVarValidation = true }
+}
+
+// GetPromptManifest returns the variables declared by the current approved version of a prompt.
+func (c *Client) GetPromptManifest(promptName string, opts ...RequestOption) (*PromptManifest, error) {
+	path := "/api/prompts/manifest?name=" + url.QueryEscape(promptName)
+	var out PromptManifest
+	if _, err := c.call(&apiRequest{op: "GetPromptManifest", feature: FeaturePromptManifest, method: http.MethodGet, path: path, opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// validateVariables checks variables against the prompt's cached manifest. Prompts without a
+// manifest, and servers that don't publish manifests, are not validated. Validation is advisory,
+// so when the manifest cannot be fetched (transport error or 5xx) it is skipped, and the miss is
+// cached briefly, rather than failing a prompt fetch the server may still serve.
+func (c *Client) validateVariables(promptName string, variables map[string]interface{}, opts []RequestOption) error {
+	if c.skipVarValidation {
+		return nil
+	}
+	var m *PromptManifest
+	if v, ok := c.manifests.Load(promptName); ok && time.Now().Before(v.(cachedManifest).expires) {
+		m = v.(cachedManifest).manifest
+	} else {
+		var err error
+		m, err = c.GetPromptManifest(promptName, append(append([]RequestOption(nil), opts...), optional())...)
+		var se *SandarbError
+		if errors.Is(err, ErrUnsupportedFeature) || (errors.As(err, &se) && se.StatusCode == http.StatusNotFound) {
+			m, err = nil, nil
+		}
+		ttl := manifestTTL
+		if err != nil && isEndpointFailure(err) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			m, err, ttl = nil, nil, manifestFailureTTL
+		}
+		if err != nil {
+			return err
+		}
+		c.manifests.Store(promptName, cachedManifest{manifest: m, expires: time.Now().Add(ttl)})
+	}
+	if m == nil {
+		return nil
+	}
+	if problems := m.Check(variables); len(problems) > 0 {
+		return &VariablesError{Prompt: promptName, Problems: problems}
+	}
+	return nil
+}
+
+// Check validates variables against the manifest. It reports required variables that are missing
+// and have no default, values of the wrong type or outside the declared enum, and undeclared names.
+func (m *PromptManifest) Check(variables map[string]interface{}) []VariableProblem {
+	var problems []VariableProblem
+	declared := make(map[string]bool, len(m.Variables))
+	for _, spec := range m.Variables {
+		declared[spec.Name] = true
+		raw, ok := variables[spec.Name]
+		if !ok {
+			if spec.Required && spec.Default == nil {
+				problems = append(problems, VariableProblem{Name: spec.Name, Message: "required variable missing"})
+			}
+			continue
+		}
+		v := toJSONValue(raw)
+		if spec.Type != "" && spec.Type != VarAny && !typeMatches(string(spec.Type), v) {
+			problems = append(problems, VariableProblem{Name: spec.Name, Message: fmt.Sprintf("expected %s, got %s", spec.Type, jsonType(v))})
+			continue
+		}
+		if len(spec.Enum) > 0 && !inEnum(spec.Enum, v) {
+			problems = append(problems, VariableProblem{Name: spec.Name, Message: fmt.Sprintf("value %v is not one of %v", v, spec.Enum)})
+		}
+	}
+	var undeclared []string
+	for name := range variables {
+		if !declared[name] {
+			undeclared = append(undeclared, name)
+		}
+	}
+	sort.Strings(undeclared)
+	for _, name := range undeclared {
+		problems = append(problems, VariableProblem{Name: name, Message: "not declared by prompt"})
+	}
+	return problems
+}
+
+func inEnum(enum []interface{}, v interface{}) bool {
+	for _, e := range enum {
+		if jsonEqual(e, v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package sandarb
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ResponseMeta is per-response metadata: rate-limit state, the server's request ID (quote it in
+// support tickets), and where the response was served from.
+type ResponseMeta struct {
+	RequestID string
+	// RateLimit and RateLimitRemaining are the quota and requests left in the current window;
+	// -1 when the server did not report them.
+	RateLimit          int
+	RateLimitRemaining int
+	// RateLimitReset is when the current window resets (zero if not reported).
+	RateLimitReset time.Time
+	// RetryAfter is the server's requested backoff on 429/503 responses (0 if not reported).
+	RetryAfter time.Duration
+	Region     string
+	ServedBy   string
+}
+
+// HasRateLimit reports whether the server reported rate-limit headers.
+func (m ResponseMeta) HasRateLimit() bool { return m.RateLimitRemaining >= 0 }
+
+// WithResponseMeta stores the metadata of the call's final response in dst, including failed
+// responses, so calls without a result (e.g. LogActivity) can be introspected too.
+func WithResponseMeta(dst *ResponseMeta) RequestOption {
+	return func(o *requestOptions) { o.meta = dst }
+}
+
+// ResponseMeta returns the metadata of the response this result came from.
+func (r *GetContextResult) ResponseMeta() ResponseMeta { return r.meta }
+
+// ResponseMeta returns the metadata of the response this result came from. For cached results
+// it describes the response that populated the cache.
+func (r *GetPromptResult) ResponseMeta() ResponseMeta { return r.meta }
+
+// ResponseMeta returns the metadata of the response this result came from.
+func (r *RawContextResult) ResponseMeta() ResponseMeta { return r.meta }
+
+// ResponseMeta returns the metadata of the response this result came from.
+func (r *TextContextResult) ResponseMeta() ResponseMeta { return r.meta }
+
+// ResponseMeta returns the metadata of the response this result came from.
+func (r *ModerationResult) ResponseMeta() ResponseMeta { return r.meta }
+
+// responseMetaFrom reads ResponseMeta from response headers. X-RateLimit-Reset may be Unix
+// seconds or seconds until reset; Retry-After may be seconds or an HTTP date.
+func responseMetaFrom(h http.Header, servedBy string) ResponseMeta {
+	now := time.Now()
+	m := ResponseMeta{
+		RequestID:          h.Get("X-Request-ID"),
+		RateLimit:          headerInt(h, "X-RateLimit-Limit"),
+		RateLimitRemaining: headerInt(h, "X-RateLimit-Remaining"),
+		Region:             h.Get(regionHeader),
+		ServedBy:           servedBy,
+	}
+	if m.RequestID == "" {
+		m.RequestID = h.Get("X-Sandarb-Request-ID")
+	}
+	if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		if reset > 1e9 {
+			m.RateLimitReset = time.Unix(reset, 0)
+		} else {
+			m.RateLimitReset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	if ra := h.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			m.RetryAfter = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(ra); err == nil && t.After(now) {
+			m.RetryAfter = t.Sub(now)
+		}
+	}
+	return m
+}
+
+func headerInt(h http.Header, key string) int {
+	n, err := strconv.Atoi(h.Get(key))
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,53 @@
+package sandarb
+
+import "time"
+
+// MetricsRecorder receives client instrumentation events. The sandarb/metrics module provides a
+// Prometheus implementation (kept in its own module so only programs that import it depend on
+// the Prometheus client); method is the SDK method name (e.g. "GetContext").
+type MetricsRecorder interface {
+	// ObserveRequest records one HTTP attempt; status is the HTTP status code, or 0 on transport error.
+	ObserveRequest(method string, status int, d time.Duration)
+	// CacheHit records a response served from a client-side cache.
+	CacheHit(method string)
+	// Retry records an attempt beyond the first (including failover to another endpoint).
+	Retry(method string)
+}
+
+// WithMetrics sets the MetricsRecorder notified of requests, cache hits, and retries.
+func WithMetrics(m MetricsRecorder) ClientOption {
+	return func(c *Client) { c.Metrics = m }
+}
+
+// statusOf extracts the HTTP status code from an error returned by do, or 0 for transport errors.
+func statusOf(err error) int {
+	if se, ok := err.(*SandarbError); ok {
+		return se.StatusCode
+	}
+	return 0
+}
+
+// MultiMetrics returns a MetricsRecorder that forwards every event to each of recs.
+func MultiMetrics(recs ...MetricsRecorder) MetricsRecorder {
+	return multiMetrics(recs)
+}
+
+type multiMetrics []MetricsRecorder
+
+func (m multiMetrics) ObserveRequest(method string, status int, d time.Duration) {
+	for _, r := range m {
+		r.ObserveRequest(method, status, d)
+	}
+}
+
+func (m multiMetrics) CacheHit(method string) {
+	for _, r := range m {
+		r.CacheHit(method)
+	}
+}
+
+func (m multiMetrics) Retry(method string) {
+	for _, r := range m {
+		r.Retry(method)
+	}
+}
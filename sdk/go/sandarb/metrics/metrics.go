@@ -0,0 +1,116 @@
+// Package metrics exports Sandarb SDK client metrics to Prometheus. It is a separate module
+// (github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb/metrics), so the SDK itself does not depend
+// on the Prometheus client.
+//
+//	client := sandarb.NewClient(metrics.WithMetricsRegistry(prometheus.DefaultRegisterer))
+//
+// To handle registration errors instead of panicking, use New and sandarb.WithMetrics:
+//
+//	col, err := metrics.New(reg)
+//	if err != nil { ... }
+//	client := sandarb.NewClient(sandarb.WithMetrics(col))
+//
+// Registered series: sandarb_requests_total, sandarb_request_duration_seconds,
+// sandarb_cache_hits_total, sandarb_retries_total (labeled by SDK method; requests also by status).
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb"
+)
+
+// Collector implements sandarb.MetricsRecorder with Prometheus counters and histograms.
+type Collector struct {
+	requests  *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+	cacheHits *prometheus.CounterVec
+	retries   *prometheus.CounterVec
+}
+
+// New creates a Collector and registers its metrics with reg. If the metrics are already
+// registered (e.g. by another client), the existing collectors are reused.
+func New(reg prometheus.Registerer) (*Collector, error) {
+	c := &Collector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sandarb_requests_total",
+			Help: "Sandarb API requests by SDK method and HTTP status (0 = transport error).",
+		}, []string{"method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "sandarb_request_duration_seconds",
+			Help:    "Sandarb API request latency by SDK method and HTTP status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "status"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sandarb_cache_hits_total",
+			Help: "Sandarb responses served from client-side cache by SDK method.",
+		}, []string{"method"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sandarb_retries_total",
+			Help: "Sandarb request attempts beyond the first (retries and failover) by SDK method.",
+		}, []string{"method"}),
+	}
+	var err error
+	if c.requests, err = register(reg, c.requests); err != nil {
+		return nil, err
+	}
+	if c.duration, err = register(reg, c.duration); err != nil {
+		return nil, err
+	}
+	if c.cacheHits, err = register(reg, c.cacheHits); err != nil {
+		return nil, err
+	}
+	if c.retries, err = register(reg, c.retries); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// register registers col with reg, returning the already-registered collector on conflict.
+func register[T prometheus.Collector](reg prometheus.Registerer, col T) (T, error) {
+	if err := reg.Register(col); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return col, err
+	}
+	return col, nil
+}
+
+// ObserveRequest implements sandarb.MetricsRecorder.
+func (c *Collector) ObserveRequest(method string, status int, d time.Duration) {
+	s := strconv.Itoa(status)
+	c.requests.WithLabelValues(method, s).Inc()
+	c.duration.WithLabelValues(method, s).Observe(d.Seconds())
+}
+
+// CacheHit implements sandarb.MetricsRecorder.
+func (c *Collector) CacheHit(method string) {
+	c.cacheHits.WithLabelValues(method).Inc()
+}
+
+// Retry implements sandarb.MetricsRecorder.
+func (c *Collector) Retry(method string) {
+	c.retries.WithLabelValues(method).Inc()
+}
+
+// WithMetricsRegistry registers Sandarb metrics with reg and instruments the client. A nil reg
+// uses prometheus.DefaultRegisterer. Like prometheus.MustRegister, it panics if registration
+// fails (e.g. a conflicting metric of the same name); use New to handle the error.
+func WithMetricsRegistry(reg prometheus.Registerer) sandarb.ClientOption {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	col, err := New(reg)
+	if err != nil {
+		panic(err)
+	}
+	return sandarb.WithMetrics(col)
+}
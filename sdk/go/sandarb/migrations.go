@@ -0,0 +1,80 @@
+package sandarb
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MigrationFunc upgrades a context payload by one schema version (fromVersion to fromVersion+1).
+type MigrationFunc func(content map[string]interface{}) (map[string]interface{}, error)
+
+// migrationRegistry holds registered migrations. Inner maps are copy-on-write: RegisterMigration
+// replaces a context's chain rather than modifying it, so a chain read under mu stays valid after
+// mu is released.
+type migrationRegistry struct {
+	mu sync.RWMutex
+	m  map[string]map[int]MigrationFunc // context name -> from version -> fn
+}
+
+// RegisterMigration registers fn to upgrade contextName payloads from schema version fromVersion
+// to fromVersion+1. GetContext (and everything built on it) applies registered migrations in
+// sequence to content the server reports at an older schema version, so a binary built against
+// the current shape keeps working while older context versions are still being served.
+// Registering the same (contextName, fromVersion) twice replaces the earlier migration.
+func (c *Client) RegisterMigration(contextName string, fromVersion int, fn MigrationFunc) {
+	c.migrations.mu.Lock()
+	defer c.migrations.mu.Unlock()
+	if c.migrations.m == nil {
+		c.migrations.m = make(map[string]map[int]MigrationFunc)
+	}
+	old := c.migrations.m[contextName]
+	chain := make(map[int]MigrationFunc, len(old)+1)
+	for v, f := range old {
+		chain[v] = f
+	}
+	chain[fromVersion] = fn
+	c.migrations.m[contextName] = chain
+}
+
+// hasMigration reports whether a migration is registered for ctxName at schema version.
+func (c *Client) hasMigration(ctxName string, version int) bool {
+	c.migrations.mu.RLock()
+	defer c.migrations.mu.RUnlock()
+	_, ok := c.migrations.m[ctxName][version]
+	return ok
+}
+
+// migrate applies the migration chain for ctxName starting at out.SchemaVersion. Content without
+// a reported schema version can't be placed in the chain and is left as-is.
+func (c *Client) migrate(ctxName string, out *GetContextResult) error {
+	if out.SchemaVersion == 0 {
+		return nil
+	}
+	c.migrations.mu.RLock()
+	chain := c.migrations.m[ctxName]
+	c.migrations.mu.RUnlock()
+	from := out.SchemaVersion
+	for {
+		fn, ok := chain[out.SchemaVersion]
+		if !ok {
+			break
+		}
+		if out.SchemaVersion == from {
+			// Migrations may modify content in place; keep the server's copy intact for deltas.
+			out.Content = cloneValue(out.Content).(map[string]interface{})
+		}
+		content, err := fn(out.Content)
+		if err != nil {
+			return fmt.Errorf("sandarb: migrate context %q from schema v%d: %w", ctxName, out.SchemaVersion, err)
+		}
+		if content == nil {
+			content = make(map[string]interface{})
+		}
+		out.Content = content
+		out.SchemaVersion++
+	}
+	if out.SchemaVersion != from {
+		out.MigratedFrom = from
+	}
+	return nil
+}
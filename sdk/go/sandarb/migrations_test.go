@@ -0,0 +1,105 @@
+package sandarb
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestMigrateAppliesChainInOrder(t *testing.T) {
+	c := NewClient()
+	c.RegisterMigration("profile", 1, func(m map[string]interface{}) (map[string]interface{}, error) {
+		m["full_name"] = m["name"]
+		delete(m, "name")
+		return m, nil
+	})
+	c.RegisterMigration("profile", 2, func(m map[string]interface{}) (map[string]interface{}, error) {
+		m["display"] = "Dr. " + m["full_name"].(string)
+		return m, nil
+	})
+	raw := map[string]interface{}{"name": "Ada"}
+	out := &GetContextResult{Content: raw, SchemaVersion: 1}
+	if err := c.migrate("profile", out); err != nil {
+		t.Fatal(err)
+	}
+	if out.SchemaVersion != 3 || out.MigratedFrom != 1 {
+		t.Errorf("SchemaVersion = %d, MigratedFrom = %d; want 3, 1", out.SchemaVersion, out.MigratedFrom)
+	}
+	if out.Content["display"] != "Dr. Ada" {
+		t.Errorf("Content = %v", out.Content)
+	}
+	if raw["name"] != "Ada" || len(raw) != 1 {
+		t.Errorf("server content modified: %v", raw)
+	}
+}
+
+func TestMigrateLeavesUnversionedAndFailsOnError(t *testing.T) {
+	c := NewClient()
+	boom := errors.New("boom")
+	c.RegisterMigration("profile", 1, func(map[string]interface{}) (map[string]interface{}, error) { return nil, boom })
+
+	out := &GetContextResult{Content: map[string]interface{}{}, SchemaVersion: 0}
+	if err := c.migrate("profile", out); err != nil || out.MigratedFrom != 0 {
+		t.Errorf("unversioned content: err %v, MigratedFrom %d", err, out.MigratedFrom)
+	}
+	out = &GetContextResult{Content: map[string]interface{}{}, SchemaVersion: 1}
+	if err := c.migrate("profile", out); !errors.Is(err, boom) {
+		t.Errorf("migrate error = %v, want %v", err, boom)
+	}
+}
+
+func TestRegisterMigrationDuringGetContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Context-Schema-Version", "1")
+		w.Write([]byte(`{"limit":10}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithoutRequestCoalescing())
+	bump := func(m map[string]interface{}) (map[string]interface{}, error) {
+		m["limit"] = m["limit"].(float64) + 1
+		return m, nil
+	}
+	c.RegisterMigration("limits", 1, bump)
+
+	stop := make(chan struct{})
+	var writers sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for {
+				for v := 2; v < 20; v++ {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					c.RegisterMigration("limits", v, bump)
+				}
+			}
+		}()
+	}
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for j := 0; j < 10; j++ {
+				res, err := c.GetContext("limits", "agent")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got := res.Content["limit"].(float64); got != 10+float64(res.SchemaVersion-1) {
+					t.Errorf("limit %v at schema v%d", got, res.SchemaVersion)
+				}
+			}
+		}()
+	}
+	readers.Wait()
+	close(stop)
+	writers.Wait()
+}
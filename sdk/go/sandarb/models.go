@@ -6,7 +6,28 @@ package sandarb
 // GetContextResult is the result of GetContext: content + context_version_id (from context_versions).
 type GetContextResult struct {
 	Content          map[string]interface{} `json:"content"`
-	ContextVersionID  *string               `json:"context_version_id,omitempty"`
+	ContextVersionID *string                `json:"context_version_id,omitempty"`
+	// Decrypted is true when the payload arrived encrypted and was decrypted client-side.
+	Decrypted bool `json:"decrypted,omitempty"`
+	// ServedBy is the base URL of the endpoint that served the response.
+	ServedBy string `json:"served_by,omitempty"`
+	// SchemaVersion is the registered schema version the content conforms to (0 if not reported).
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// MigratedFrom is the schema version the server sent when client-side migrations upgraded
+	// the content to SchemaVersion (0 if no migration ran). See Client.RegisterMigration.
+	MigratedFrom int `json:"migrated_from,omitempty"`
+	// NotModified is true when GetContextIfChanged found the context unchanged and reused the previous content.
+	NotModified bool `json:"not_modified,omitempty"`
+	// Delta is true when GetContextIfChanged received a JSON Patch and applied it to the previous content.
+	Delta bool `json:"delta,omitempty"`
+	// Cached is true when the result was served from the client's CacheStore (see WithCacheStore).
+	Cached bool `json:"cached,omitempty"`
+
+	raw map[string]interface{} // content as served, before decryption and migration; base for deltas
+	// Region is the data residency region the server reported serving from.
+	Region string `json:"region,omitempty"`
+
+	meta ResponseMeta
 }
 
 // GetPromptResult is the result of GetPrompt: compiled prompt text and version info (from prompt_versions).
@@ -15,4 +36,15 @@ type GetPromptResult struct {
 	Version      int     `json:"version"`
 	Model        *string `json:"model,omitempty"`
 	SystemPrompt *string `json:"system_prompt,omitempty"`
+	// ServedBy is the base URL of the endpoint that served the response.
+	ServedBy string `json:"served_by,omitempty"`
+	// Region is the data residency region the server reported serving from.
+	Region string `json:"region,omitempty"`
+	// Cached is true when the result was served from the client's prompt cache or CacheStore
+	// (see WithPromptCache and WithCacheStore).
+	Cached bool `json:"cached,omitempty"`
+	// Tokens is the prompt's token accounting when requested with WithTokenBudget.
+	Tokens *TokenUsage `json:"tokens,omitempty"`
+
+	meta ResponseMeta
 }
@@ -0,0 +1,70 @@
+package sandarb
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ModerationVerdict is the action a governance policy takes on moderated text.
+type ModerationVerdict string
+
+const (
+	VerdictAllow ModerationVerdict = "allow"
+	VerdictWarn  ModerationVerdict = "warn"
+	VerdictBlock ModerationVerdict = "block"
+)
+
+// PolicyVerdict is one policy's decision on moderated text.
+type PolicyVerdict struct {
+	Policy  string            `json:"policy"`
+	Verdict ModerationVerdict `json:"verdict"`
+	Reason  string            `json:"reason,omitempty"`
+	// Categories lists the categories that triggered the verdict.
+	Categories []string `json:"categories,omitempty"`
+}
+
+// ModerationResult holds per-category scores (0..1) and the policy verdicts for a piece of text.
+type ModerationResult struct {
+	Flagged  bool               `json:"flagged"`
+	Scores   map[string]float64 `json:"scores"`
+	Verdicts []PolicyVerdict    `json:"verdicts"`
+	ServedBy string             `json:"-"`
+	Region   string             `json:"-"`
+
+	meta ResponseMeta
+}
+
+// Blocked reports whether any policy returned VerdictBlock.
+func (r *ModerationResult) Blocked() bool {
+	for _, v := range r.Verdicts {
+		if v.Verdict == VerdictBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// Moderate screens text (user input or model output) against the agent's moderation policies.
+// The check is recorded under traceID so pre- and post-screening land in the same audit trail.
+func (c *Client) Moderate(agentID, traceID, text string, opts ...RequestOption) (*ModerationResult, error) {
+	if agentID == "" {
+		agentID = c.AgentID
+	}
+	if agentID == "" {
+		return nil, fmt.Errorf("sandarb: agent_id is required for Moderate (or set SANDARB_AGENT_ID)")
+	}
+	if traceID == "" {
+		traceID = newTraceID()
+	}
+	body := map[string]string{"agent_id": agentID, "trace_id": traceID, "text": text}
+	var out ModerationResult
+	r := &apiRequest{op: "Moderate", feature: FeatureModeration, method: http.MethodPost, path: "/api/moderation", agentID: agentID, traceID: traceID, opts: opts}
+	servedBy, err := c.call(r, body, &out)
+	if err != nil {
+		return nil, err
+	}
+	out.ServedBy = servedBy
+	out.Region = r.servedRegion
+	out.meta = r.meta
+	return &out, nil
+}
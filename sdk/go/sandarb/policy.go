@@ -0,0 +1,268 @@
+package sandarb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PolicyBundle is a compiled policy bundle downloaded for local evaluation.
+type PolicyBundle struct {
+	Name     string `json:"name"`
+	Revision string `json:"revision"`
+	// Engine is the policy language, e.g. "rego" or "cel".
+	Engine string `json:"engine"`
+	// Modules maps module paths to policy source (Rego modules or CEL expressions).
+	Modules map[string]string `json:"modules"`
+	// Data is the bundle's base document, if any.
+	Data json.RawMessage `json:"data,omitempty"`
+	// Entrypoints lists the queries the bundle is compiled for (e.g. "data.sandarb.allow").
+	Entrypoints []string `json:"entrypoints,omitempty"`
+}
+
+// PolicyDecision is the outcome of evaluating a policy query.
+type PolicyDecision struct {
+	Allowed bool        `json:"allowed"`
+	Reasons []string    `json:"reasons,omitempty"`
+	Result  interface{} `json:"result,omitempty"`
+}
+
+// PolicyEvaluator compiles bundles and evaluates queries locally. The SDK doesn't bundle a policy
+// engine: the sandarb/policy/rego module adapts OPA (it is a separate module, so only programs
+// that use it depend on OPA), and other engines such as cel-go can be adapted the same way.
+//
+// Load is called for each new revision while Evaluate may be running concurrently, so
+// implementations should swap compiled state atomically.
+type PolicyEvaluator interface {
+	Load(bundle *PolicyBundle) error
+	Evaluate(ctx context.Context, query string, input map[string]interface{}) (*PolicyDecision, error)
+}
+
+// LocalPolicyOptions configures NewLocalPolicy.
+type LocalPolicyOptions struct {
+	// RefreshInterval is how often the bundle is checked for a new revision (default 1m).
+	RefreshInterval time.Duration
+	// DisableDecisionLog turns off asynchronous decision logging to the server.
+	DisableDecisionLog bool
+	// DecisionLogBuffer bounds queued decisions; when full, new decisions are dropped and counted (default 1000).
+	DecisionLogBuffer int
+	// DecisionLogInterval is the maximum time decisions wait before being sent (default 5s).
+	DecisionLogInterval time.Duration
+	// OnRefreshError is called when a refresh fails; the previous revision stays in use.
+	OnRefreshError func(error)
+}
+
+// DecisionLogEntry records one local policy evaluation.
+type DecisionLogEntry struct {
+	DecisionID string `json:"decision_id"`
+	Bundle     string `json:"bundle"`
+	Revision   string `json:"revision"`
+	Query      string `json:"query"`
+	// Input is a copy of the evaluated input, with secret scanning (WithSecretScanning) and the
+	// payload limit applied as for activity records; it is omitted if scanning blocks it.
+	Input      map[string]interface{} `json:"input,omitempty"`
+	Decision   *PolicyDecision        `json:"decision,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	DurationUS int64                  `json:"duration_us"`
+}
+
+// LocalPolicy evaluates a downloaded policy bundle in-process, refreshing it in the background
+// and logging decisions back to the server asynchronously. Both stop when the client is closed.
+type LocalPolicy struct {
+	client    *Client
+	bundle    string
+	evaluator PolicyEvaluator
+	opts      LocalPolicyOptions
+
+	mu       sync.RWMutex
+	revision string
+
+	decisions chan DecisionLogEntry
+	dropped   int64
+}
+
+// NewLocalPolicy downloads bundle, loads it into ev, and starts background refresh and decision
+// logging. It fails if the initial download or load fails.
+func (c *Client) NewLocalPolicy(bundle string, ev PolicyEvaluator, opts LocalPolicyOptions) (*LocalPolicy, error) {
+	if bundle == "" || ev == nil {
+		return nil, fmt.Errorf("sandarb: bundle name and evaluator are required for NewLocalPolicy")
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = time.Minute
+	}
+	if opts.DecisionLogBuffer <= 0 {
+		opts.DecisionLogBuffer = 1000
+	}
+	if opts.DecisionLogInterval <= 0 {
+		opts.DecisionLogInterval = 5 * time.Second
+	}
+	p := &LocalPolicy{client: c, bundle: bundle, evaluator: ev, opts: opts}
+	if err := p.refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	c.goBackground(p.refreshLoop)
+	if !opts.DisableDecisionLog {
+		p.decisions = make(chan DecisionLogEntry, opts.DecisionLogBuffer)
+		c.goBackground(p.logLoop)
+	}
+	return p, nil
+}
+
+// Revision returns the bundle revision currently loaded.
+func (p *LocalPolicy) Revision() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.revision
+}
+
+// Dropped returns the number of decisions not logged because the queue was full.
+func (p *LocalPolicy) Dropped() int64 { return atomic.LoadInt64(&p.dropped) }
+
+// Evaluate evaluates query against input with the loaded bundle, without a network round trip.
+func (p *LocalPolicy) Evaluate(ctx context.Context, query string, input map[string]interface{}) (*PolicyDecision, error) {
+	start := time.Now()
+	d, err := p.evaluator.Evaluate(ctx, query, input)
+	if p.decisions != nil {
+		e := DecisionLogEntry{
+			DecisionID: newTraceID(), Bundle: p.bundle, Revision: p.Revision(), Query: query, Input: p.decisionInput(input),
+			Decision: d, Timestamp: start.UTC(), DurationUS: time.Since(start).Microseconds(),
+		}
+		if err != nil {
+			e.Error = err.Error()
+		}
+		select {
+		case p.decisions <- e:
+		default:
+			atomic.AddInt64(&p.dropped, 1)
+		}
+	}
+	return d, err
+}
+
+// decisionInput copies input for the decision log, which is encoded later on another goroutine,
+// so the caller may reuse or modify input once Evaluate returns.
+func (p *LocalPolicy) decisionInput(input map[string]interface{}) map[string]interface{} {
+	if input == nil {
+		return nil
+	}
+	b, err := json.Marshal(input)
+	if err != nil {
+		return nil
+	}
+	var in map[string]interface{}
+	if json.Unmarshal(b, &in) != nil {
+		return nil
+	}
+	c := p.client
+	if c.secrets != nil {
+		scanned, err := c.secrets.scan("LogPolicyDecision", map[string]map[string]interface{}{"input": in})
+		if err != nil {
+			return nil
+		}
+		in = scanned["input"]
+	}
+	if pl := c.payloadLimit; pl != nil && len(b) > pl.maxBytes-truncationReserve {
+		if b, err = json.Marshal(in); err == nil && len(b) > pl.maxBytes-truncationReserve {
+			in = pl.truncate(b, pl.maxBytes-truncationReserve)
+		}
+	}
+	return in
+}
+
+// refresh downloads the bundle if its revision changed and loads it into the evaluator.
+func (p *LocalPolicy) refresh(ctx context.Context) error {
+	path := "/api/policies/bundles/" + url.PathEscape(p.bundle)
+	opts := []RequestOption{WithContext(ctx)}
+	if rev := p.Revision(); rev != "" {
+		opts = append(opts, WithHeader("If-None-Match", strconv.Quote(rev)))
+	}
+	var b PolicyBundle
+	_, err := p.client.call(&apiRequest{op: "GetPolicyBundle", feature: FeaturePolicyBundles, method: http.MethodGet, path: path, opts: opts}, nil, &b)
+	var se *SandarbError
+	if errors.As(err, &se) && se.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if b.Revision != "" && b.Revision == p.Revision() {
+		return nil
+	}
+	if err := p.evaluator.Load(&b); err != nil {
+		return fmt.Errorf("sandarb: load policy bundle %q revision %q: %w", p.bundle, b.Revision, err)
+	}
+	p.mu.Lock()
+	p.revision = b.Revision
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *LocalPolicy) refreshLoop(ctx context.Context) {
+	t := time.NewTicker(p.opts.RefreshInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := p.refresh(ctx); err != nil && ctx.Err() == nil && p.opts.OnRefreshError != nil {
+				p.opts.OnRefreshError(err)
+			}
+		}
+	}
+}
+
+// logLoop batches queued decisions to the server. On shutdown it drains the queue and sends a
+// final batch bounded by Close's context.
+func (p *LocalPolicy) logLoop(ctx context.Context) {
+	const maxBatch = 500
+	t := time.NewTicker(p.opts.DecisionLogInterval)
+	defer t.Stop()
+	var batch []DecisionLogEntry
+	// flush sends the batch, returning how many decisions were dropped.
+	flush := func(ctx context.Context) int {
+		n := len(batch)
+		if n == 0 {
+			return 0
+		}
+		_, err := p.client.call(&apiRequest{op: "LogPolicyDecisions", feature: FeaturePolicyBundles, method: http.MethodPost, path: "/api/policies/decisions", opts: []RequestOption{WithContext(ctx)}}, batch, nil)
+		batch = batch[:0]
+		if err != nil {
+			atomic.AddInt64(&p.dropped, int64(n))
+			return n
+		}
+		return 0
+	}
+	for {
+		select {
+		case e := <-p.decisions:
+			if batch = append(batch, e); len(batch) >= maxBatch {
+				flush(ctx)
+			}
+		case <-t.C:
+			flush(ctx)
+		case <-ctx.Done():
+		drain:
+			for {
+				select {
+				case e := <-p.decisions:
+					batch = append(batch, e)
+				default:
+					break drain
+				}
+			}
+			fctx, cancel := p.client.shutdownContext()
+			p.client.reportUndelivered("policy_decisions", flush(fctx))
+			cancel()
+			return
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package rego evaluates Sandarb policy bundles with Open Policy Agent. It is a separate module
+// (github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb/policy/rego), so the SDK itself does not
+// depend on OPA.
+//
+//	policy, err := client.NewLocalPolicy("agent-guardrails", rego.New(), sandarb.LocalPolicyOptions{})
+//	if err != nil { ... }
+//	d, err := policy.Evaluate(ctx, "data.sandarb.allow", input)
+//
+// Each of the bundle's entrypoints is prepared when the bundle is loaded. A query result that is
+// a boolean is the decision; an object result is read for "allow" (or "allowed") and "reasons"
+// (or "deny"), and is returned as PolicyDecision.Result either way.
+package rego
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	opa "github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+
+	"github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb"
+)
+
+// Evaluator implements sandarb.PolicyEvaluator with OPA's rego package.
+type Evaluator struct {
+	prepared atomic.Value // map[string]opa.PreparedEvalQuery, keyed by entrypoint
+}
+
+// New returns an Evaluator with no bundle loaded.
+func New() *Evaluator {
+	return &Evaluator{}
+}
+
+// Load implements sandarb.PolicyEvaluator. The bundle's entrypoints are compiled together and
+// swapped in atomically, so evaluations in progress finish against the previous revision.
+func (e *Evaluator) Load(b *sandarb.PolicyBundle) error {
+	if b.Engine != "" && b.Engine != "rego" {
+		return fmt.Errorf("sandarb: rego: bundle %q uses engine %q", b.Name, b.Engine)
+	}
+	if len(b.Entrypoints) == 0 {
+		return fmt.Errorf("sandarb: rego: bundle %q has no entrypoints", b.Name)
+	}
+	data := map[string]interface{}{}
+	if len(b.Data) > 0 {
+		if err := json.Unmarshal(b.Data, &data); err != nil {
+			return fmt.Errorf("sandarb: rego: bundle %q data: %w", b.Name, err)
+		}
+	}
+	store := inmem.NewFromObject(data)
+	paths := make([]string, 0, len(b.Modules))
+	for p := range b.Modules {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	prepared := make(map[string]opa.PreparedEvalQuery, len(b.Entrypoints))
+	for _, query := range b.Entrypoints {
+		opts := []func(*opa.Rego){opa.Query(query), opa.Store(store)}
+		for _, p := range paths {
+			opts = append(opts, opa.Module(p, b.Modules[p]))
+		}
+		pq, err := opa.New(opts...).PrepareForEval(context.Background())
+		if err != nil {
+			return fmt.Errorf("sandarb: rego: bundle %q entrypoint %q: %w", b.Name, query, err)
+		}
+		prepared[query] = pq
+	}
+	e.prepared.Store(prepared)
+	return nil
+}
+
+// Evaluate implements sandarb.PolicyEvaluator. query must be one of the bundle's entrypoints.
+// A query with no result (an undefined rule) is a deny.
+func (e *Evaluator) Evaluate(ctx context.Context, query string, input map[string]interface{}) (*sandarb.PolicyDecision, error) {
+	prepared, _ := e.prepared.Load().(map[string]opa.PreparedEvalQuery)
+	pq, ok := prepared[query]
+	if !ok {
+		return nil, fmt.Errorf("sandarb: rego: query %q is not an entrypoint of the loaded bundle", query)
+	}
+	rs, err := pq.Eval(ctx, opa.EvalInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("sandarb: rego: evaluate %q: %w", query, err)
+	}
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return &sandarb.PolicyDecision{Allowed: false}, nil
+	}
+	return decision(rs[0].Expressions[0].Value), nil
+}
+
+// decision maps a query result onto a PolicyDecision.
+func decision(v interface{}) *sandarb.PolicyDecision {
+	d := &sandarb.PolicyDecision{Result: v}
+	switch t := v.(type) {
+	case bool:
+		d.Allowed = t
+	case map[string]interface{}:
+		if allow, ok := t["allow"].(bool); ok {
+			d.Allowed = allow
+		} else if allow, ok := t["allowed"].(bool); ok {
+			d.Allowed = allow
+		}
+		for _, key := range []string{"reasons", "deny"} {
+			if list, ok := t[key].([]interface{}); ok {
+				for _, r := range list {
+					if s, ok := r.(string); ok {
+						d.Reasons = append(d.Reasons, s)
+					}
+				}
+			}
+		}
+	}
+	return d
+}
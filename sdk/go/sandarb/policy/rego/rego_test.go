@@ -0,0 +1,71 @@
+package rego
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb"
+)
+
+const testModule = `package sandarb
+
+import rego.v1
+
+default allow := false
+
+allow if input.amount <= data.limits.max
+
+decision := {"allow": allow, "reasons": reasons}
+
+reasons contains "amount over limit" if input.amount > data.limits.max
+`
+
+func testBundle() *sandarb.PolicyBundle {
+	return &sandarb.PolicyBundle{
+		Name:        "payments",
+		Engine:      "rego",
+		Modules:     map[string]string{"sandarb.rego": testModule},
+		Data:        []byte(`{"limits":{"max":100}}`),
+		Entrypoints: []string{"data.sandarb.allow", "data.sandarb.decision", "data.sandarb.missing"},
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	e := New()
+	if err := e.Load(testBundle()); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	d, err := e.Evaluate(ctx, "data.sandarb.allow", map[string]interface{}{"amount": 50})
+	if err != nil || !d.Allowed {
+		t.Errorf("allow under limit = %+v, %v", d, err)
+	}
+	d, err = e.Evaluate(ctx, "data.sandarb.decision", map[string]interface{}{"amount": 500})
+	if err != nil || d.Allowed || len(d.Reasons) != 1 || d.Reasons[0] != "amount over limit" {
+		t.Errorf("decision over limit = %+v, %v", d, err)
+	}
+	if d, err = e.Evaluate(ctx, "data.sandarb.missing", nil); err != nil || d.Allowed {
+		t.Errorf("undefined rule = %+v, %v; want a deny", d, err)
+	}
+	if _, err := e.Evaluate(ctx, "data.other", nil); err == nil {
+		t.Error("query outside the bundle's entrypoints evaluated")
+	}
+}
+
+func TestEvaluatorLoadErrors(t *testing.T) {
+	b := testBundle()
+	b.Engine = "cel"
+	if err := New().Load(b); err == nil {
+		t.Error("cel bundle loaded")
+	}
+	b = testBundle()
+	b.Modules["broken.rego"] = "package broken\n\nallow if {"
+	e := New()
+	if err := e.Load(b); err == nil {
+		t.Error("bundle with a syntax error loaded")
+	}
+	if _, err := e.Evaluate(context.Background(), "data.sandarb.allow", nil); err == nil {
+		t.Error("evaluated before any bundle loaded")
+	}
+}
@@ -0,0 +1,118 @@
+package sandarb
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PromptCacheOptions bounds the compiled-prompt cache enabled by WithPromptCache.
+type PromptCacheOptions struct {
+	// TTL is how long a compiled prompt is reused (default 5m). Entries for the latest version
+	// pick up newly approved versions once they expire.
+	TTL time.Duration
+	// MaxEntries caps the number of cached prompts (default 1024).
+	MaxEntries int
+	// MaxBytes caps the approximate memory held by cached prompts (default 16 MiB).
+	MaxBytes int64
+}
+
+// WithPromptCache caches compiled prompts by (agent, name, version, hash of variables), so batch
+// jobs that repeat the same prompt and variables don't recompile it on the server each time. The
+// least recently used entries are evicted when a bound is reached. Cache hits are reported via
+// MetricsRecorder.CacheHit and marked with GetPromptResult.Cached.
+func WithPromptCache(opts PromptCacheOptions) ClientOption {
+	return func(c *Client) { c.promptCache = newPromptCache(opts) }
+}
+
+type promptCache struct {
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int64
+
+	mu    sync.Mutex
+	ll    *list.List // front is most recently used
+	items map[string]*list.Element
+	bytes int64
+}
+
+type promptCacheEntry struct {
+	key     string
+	result  GetPromptResult
+	size    int64
+	expires time.Time
+}
+
+func newPromptCache(opts PromptCacheOptions) *promptCache {
+	if opts.TTL <= 0 {
+		opts.TTL = 5 * time.Minute
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 1024
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = 16 << 20
+	}
+	return &promptCache{ttl: opts.TTL, maxEntries: opts.MaxEntries, maxBytes: opts.MaxBytes, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// promptCacheKey identifies a compiled prompt. version 0 means the latest version.
+func promptCacheKey(agentID, name string, version int, variables map[string]interface{}) (string, bool) {
+	b, err := json.Marshal(variables) // map keys are sorted, so equal variables hash equally
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(b)
+	return agentID + "\x00" + name + "\x00" + strconv.Itoa(version) + "\x00" + hex.EncodeToString(sum[:]), true
+}
+
+func (pc *promptCache) get(key string) (*GetPromptResult, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	el, ok := pc.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*promptCacheEntry)
+	if time.Now().After(e.expires) {
+		pc.remove(el)
+		return nil, false
+	}
+	pc.ll.MoveToFront(el)
+	out := e.result
+	return &out, true
+}
+
+func (pc *promptCache) put(key string, r *GetPromptResult) {
+	size := int64(len(key) + len(r.Content) + len(r.ServedBy) + len(r.Region) + 128)
+	if r.Model != nil {
+		size += int64(len(*r.Model))
+	}
+	if r.SystemPrompt != nil {
+		size += int64(len(*r.SystemPrompt))
+	}
+	if size > pc.maxBytes {
+		return
+	}
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if el, ok := pc.items[key]; ok {
+		pc.remove(el)
+	}
+	e := &promptCacheEntry{key: key, result: *r, size: size, expires: time.Now().Add(pc.ttl)}
+	pc.items[key] = pc.ll.PushFront(e)
+	pc.bytes += size
+	for pc.ll.Len() > pc.maxEntries || pc.bytes > pc.maxBytes {
+		pc.remove(pc.ll.Back())
+	}
+}
+
+func (pc *promptCache) remove(el *list.Element) {
+	e := pc.ll.Remove(el).(*promptCacheEntry)
+	delete(pc.items, e.key)
+	pc.bytes -= e.size
+}
@@ -0,0 +1,133 @@
+package sandarb
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPromptCacheKeyIgnoresVariableOrder(t *testing.T) {
+	a, _ := promptCacheKey("agent", "greet", 0, map[string]interface{}{"a": 1, "b": "x"})
+	b, _ := promptCacheKey("agent", "greet", 0, map[string]interface{}{"b": "x", "a": 1})
+	if a != b {
+		t.Errorf("keys differ for equal variables: %q, %q", a, b)
+	}
+	c, _ := promptCacheKey("agent", "greet", 0, map[string]interface{}{"a": 2, "b": "x"})
+	if a == c {
+		t.Error("keys equal for different variables")
+	}
+	d, _ := promptCacheKey("other", "greet", 0, map[string]interface{}{"a": 1, "b": "x"})
+	if a == d {
+		t.Error("keys equal for different agents")
+	}
+}
+
+func TestPromptCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	pc := newPromptCache(PromptCacheOptions{MaxEntries: 2})
+	pc.put("a", &GetPromptResult{Content: "A"})
+	pc.put("b", &GetPromptResult{Content: "B"})
+	if _, ok := pc.get("a"); !ok { // a is now the most recently used
+		t.Fatal("a missing")
+	}
+	pc.put("c", &GetPromptResult{Content: "C"})
+	if _, ok := pc.get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	for _, k := range []string{"a", "c"} {
+		if _, ok := pc.get(k); !ok {
+			t.Errorf("%s missing", k)
+		}
+	}
+}
+
+func TestPromptCacheBounds(t *testing.T) {
+	pc := newPromptCache(PromptCacheOptions{MaxBytes: 1024})
+	pc.put("big", &GetPromptResult{Content: string(make([]byte, 2048))})
+	if _, ok := pc.get("big"); ok {
+		t.Error("entry larger than MaxBytes was cached")
+	}
+	for i := 0; i < 10; i++ {
+		pc.put(strconv.Itoa(i), &GetPromptResult{Content: string(make([]byte, 200))})
+	}
+	if pc.bytes > 1024 {
+		t.Errorf("cache holds %d bytes, over the 1024 byte bound", pc.bytes)
+	}
+	if _, ok := pc.get("9"); !ok {
+		t.Error("most recent entry missing")
+	}
+
+	pc.put("old", &GetPromptResult{Content: "x"})
+	pc.mu.Lock()
+	pc.items["old"].Value.(*promptCacheEntry).expires = time.Now().Add(-time.Second)
+	pc.mu.Unlock()
+	if _, ok := pc.get("old"); ok {
+		t.Error("expired entry returned")
+	}
+}
+
+func TestPromptCacheReturnsCopies(t *testing.T) {
+	pc := newPromptCache(PromptCacheOptions{})
+	r := &GetPromptResult{Content: "hello"}
+	pc.put("k", r)
+	r.Content = "changed by caller"
+	got, _ := pc.get("k")
+	got.Content = "changed by reader"
+	if again, _ := pc.get("k"); again.Content != "hello" {
+		t.Errorf("cached content = %q, want %q", again.Content, "hello")
+	}
+}
+
+func TestGetPromptUsesPromptCache(t *testing.T) {
+	var pulls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/prompts/pull" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&pulls, 1)
+		w.Write([]byte(`{"success":true,"data":{"content":"Hello Ada","version":3}}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithAgentID("agent"), WithPromptCache(PromptCacheOptions{}), WithoutVariableValidation())
+	vars := map[string]interface{}{"name": "Ada"}
+	first, err := c.GetPrompt("greet", vars, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached {
+		t.Error("first call reported as cached")
+	}
+	first.Content = "mutated"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := c.GetPrompt("greet", vars, "", "")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !r.Cached || r.Content != "Hello Ada" || r.Version != 3 {
+				t.Errorf("cached result = %+v", r)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&pulls); n != 1 {
+		t.Errorf("server saw %d pulls, want 1", n)
+	}
+
+	if _, err := c.GetPrompt("greet", map[string]interface{}{"name": "Grace"}, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&pulls); n != 2 {
+		t.Errorf("server saw %d pulls after new variables, want 2", n)
+	}
+}
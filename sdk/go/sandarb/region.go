@@ -0,0 +1,89 @@
+package sandarb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// regionHeader carries the pinned region on requests and the serving region on responses.
+const regionHeader = "X-Sandarb-Region"
+
+// ErrResidencyViolation is matched (via errors.Is) by *ResidencyError.
+var ErrResidencyViolation = errors.New("sandarb: data residency violation")
+
+// ResidencyError is returned when a call would be, or was, served outside the pinned region.
+// Responses served from another region are discarded without being decoded.
+type ResidencyError struct {
+	Region string // pinned region
+	// ServedRegion is the region reported by the server; empty if the call was rejected before
+	// sending or, under WithStrictRegion, the response did not report a region.
+	ServedRegion string
+	// Endpoint is the base URL that served the response; empty if the call was rejected before sending.
+	Endpoint string
+}
+
+func (e *ResidencyError) Error() string {
+	if e.Endpoint == "" {
+		return fmt.Sprintf("sandarb: no endpoint configured for region %q", e.Region)
+	}
+	if e.ServedRegion == "" {
+		return fmt.Sprintf("sandarb: %s did not report its region, client is pinned to %q", e.Endpoint, e.Region)
+	}
+	return fmt.Sprintf("sandarb: %s served from region %q, client is pinned to %q", e.Endpoint, e.ServedRegion, e.Region)
+}
+
+// Is makes errors.Is(err, ErrResidencyViolation) true.
+func (e *ResidencyError) Is(target error) bool { return target == ErrResidencyViolation }
+
+// WithRegion pins the client to a data residency region (e.g. "eu"). Requests carry the region so
+// the server can refuse them, and responses served from another region fail with *ResidencyError.
+// A served region matches when it equals the pinned one or is a sub-region of it ("eu-west-1" for "eu").
+// Responses that do not report a region are accepted unless WithStrictRegion is set.
+//
+// The served region is only known from the response, so the check runs after the request, body
+// included, reached the server. To keep request data from leaving the region, also configure
+// WithRegionEndpoints: requests then only go to the pinned region's endpoints.
+func WithRegion(region string) ClientOption {
+	return func(c *Client) { c.region = strings.ToLower(region) }
+}
+
+// WithStrictRegion makes responses without an X-Sandarb-Region header fail with *ResidencyError
+// when the client is pinned with WithRegion, for deployments where every server reports its region.
+func WithStrictRegion() ClientOption {
+	return func(c *Client) { c.strictRegion = true }
+}
+
+// WithRegionEndpoints maps regions to their base URLs (the first is primary, the rest are
+// fallbacks). With WithRegion, only the pinned region's URLs are used, replacing BaseURL and
+// FallbackURLs, and calls fail before sending if the region has none.
+func WithRegionEndpoints(endpoints map[string][]string) ClientOption {
+	return func(c *Client) {
+		c.regionEndpoints = make(map[string][]string, len(endpoints))
+		for r, urls := range endpoints {
+			c.regionEndpoints[strings.ToLower(r)] = urls
+		}
+	}
+}
+
+// Region returns the region the client is pinned to, or "" if unpinned.
+func (c *Client) Region() string { return c.region }
+
+// baseURLs returns the endpoints to use, in failover order.
+func (c *Client) baseURLs() []string {
+	if c.region != "" && c.regionEndpoints != nil {
+		return c.regionEndpoints[c.region]
+	}
+	return append([]string{c.BaseURL}, c.FallbackURLs...)
+}
+
+// checkResidency rejects a response served from outside the pinned region, or that does not
+// report its region under WithStrictRegion.
+func (c *Client) checkResidency(resp *http.Response, endpoint string) error {
+	served := strings.ToLower(resp.Header.Get(regionHeader))
+	if c.region == "" || (served == "" && !c.strictRegion) || served == c.region || strings.HasPrefix(served, c.region+"-") {
+		return nil
+	}
+	return &ResidencyError{Region: c.region, ServedRegion: served, Endpoint: endpoint}
+}
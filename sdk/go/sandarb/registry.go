@@ -0,0 +1,212 @@
+package sandarb
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// registryPageSize is the page size used when listing every context or prompt.
+const registryPageSize = 200
+
+// ContextRecord is a context as stored in the registry (contexts table), as used by
+// administration and sync tooling. Content is the active version's content (GetContextRecord only).
+type ContextRecord struct {
+	ID                 string                 `json:"id,omitempty"`
+	Name               string                 `json:"name"`
+	Description        string                 `json:"description,omitempty"`
+	Tags               []string               `json:"tags,omitempty"`
+	OrgID              string                 `json:"orgId,omitempty"`
+	DataClassification string                 `json:"dataClassification,omitempty"`
+	RegulatoryHooks    []string               `json:"regulatoryHooks,omitempty"`
+	Content            map[string]interface{} `json:"content,omitempty"`
+}
+
+// ContextRevision is one version of a context (context_versions), approved or not.
+type ContextRevision struct {
+	ID            string                 `json:"id"`
+	Version       int                    `json:"version"`
+	Content       map[string]interface{} `json:"content"`
+	Status        string                 `json:"status"`
+	IsActive      bool                   `json:"isActive"`
+	CommitMessage string                 `json:"commitMessage,omitempty"`
+	CreatedBy     string                 `json:"createdBy,omitempty"`
+	CreatedAt     string                 `json:"createdAt,omitempty"`
+}
+
+// PromptRecord is a prompt as stored in the registry (prompts table), with its versions newest first.
+type PromptRecord struct {
+	ID             string          `json:"id,omitempty"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description,omitempty"`
+	Tags           []string        `json:"tags,omitempty"`
+	CurrentVersion *PromptVersion  `json:"currentVersion,omitempty"`
+	Versions       []PromptVersion `json:"versions,omitempty"`
+}
+
+// PromptVersion is one version of a prompt (prompt_versions).
+type PromptVersion struct {
+	ID            string  `json:"id,omitempty"`
+	Version       int     `json:"version,omitempty"`
+	Content       string  `json:"content"`
+	SystemPrompt  *string `json:"systemPrompt,omitempty"`
+	Model         *string `json:"model,omitempty"`
+	Status        string  `json:"status,omitempty"`
+	CommitMessage string  `json:"commitMessage,omitempty"`
+}
+
+// RevisionOptions describes a new context revision or prompt version.
+type RevisionOptions struct {
+	CommitMessage string
+	// AutoApprove activates the revision immediately instead of submitting it for approval.
+	AutoApprove bool
+	CreatedBy   string
+}
+
+func (o RevisionOptions) body() map[string]interface{} {
+	b := map[string]interface{}{"autoApprove": o.AutoApprove}
+	if o.CommitMessage != "" {
+		b["commitMessage"] = o.CommitMessage
+	}
+	if o.CreatedBy != "" {
+		b["createdBy"] = o.CreatedBy
+	}
+	return b
+}
+
+// ListContextRecords returns every context in the registry (without content).
+func (c *Client) ListContextRecords(opts ...RequestOption) ([]ContextRecord, error) {
+	var all []ContextRecord
+	for offset := 0; ; offset += registryPageSize {
+		var page struct {
+			Contexts []ContextRecord `json:"contexts"`
+			Total    int             `json:"total"`
+		}
+		path := "/api/contexts?limit=" + strconv.Itoa(registryPageSize) + "&offset=" + strconv.Itoa(offset)
+		if _, err := c.call(&apiRequest{op: "ListContextRecords", method: http.MethodGet, path: path, opts: opts}, nil, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Contexts...)
+		if len(page.Contexts) < registryPageSize || len(all) >= page.Total {
+			return all, nil
+		}
+	}
+}
+
+// GetContextRecord returns a context by registry ID, with its active content.
+func (c *Client) GetContextRecord(id string, opts ...RequestOption) (*ContextRecord, error) {
+	var out ContextRecord
+	if _, err := c.call(&apiRequest{op: "GetContextRecord", method: http.MethodGet, path: "/api/contexts/" + url.PathEscape(id), opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListContextRevisions returns a context's revisions, newest first.
+func (c *Client) ListContextRevisions(id string, opts ...RequestOption) ([]ContextRevision, error) {
+	var out []ContextRevision
+	path := "/api/contexts/" + url.PathEscape(id) + "/revisions"
+	if _, err := c.call(&apiRequest{op: "ListContextRevisions", method: http.MethodGet, path: path, opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateContext registers a new context; rec.Content becomes its approved initial version.
+func (c *Client) CreateContext(rec ContextRecord, opts ...RequestOption) (*ContextRecord, error) {
+	if rec.Name == "" {
+		return nil, fmt.Errorf("sandarb: name is required for CreateContext")
+	}
+	rec.ID = ""
+	var out ContextRecord
+	if _, err := c.call(&apiRequest{op: "CreateContext", method: http.MethodPost, path: "/api/contexts", opts: opts}, rec, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateContext updates a context's description, data classification, and regulatory hooks.
+// Content changes go through CreateContextRevision so they are versioned and approved.
+func (c *Client) UpdateContext(id string, rec ContextRecord, opts ...RequestOption) (*ContextRecord, error) {
+	body := map[string]interface{}{"description": rec.Description, "regulatoryHooks": rec.RegulatoryHooks}
+	if rec.DataClassification != "" {
+		body["dataClassification"] = rec.DataClassification
+	}
+	var out ContextRecord
+	if _, err := c.call(&apiRequest{op: "UpdateContext", method: http.MethodPut, path: "/api/contexts/" + url.PathEscape(id), opts: opts}, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateContextRevision adds a new revision of a context's content.
+func (c *Client) CreateContextRevision(id string, content map[string]interface{}, ro RevisionOptions, opts ...RequestOption) (*ContextRevision, error) {
+	body := ro.body()
+	body["content"] = content
+	var out ContextRevision
+	path := "/api/contexts/" + url.PathEscape(id) + "/revisions"
+	if _, err := c.call(&apiRequest{op: "CreateContextRevision", method: http.MethodPost, path: path, opts: opts}, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListPromptRecords returns every prompt in the registry (without versions).
+func (c *Client) ListPromptRecords(opts ...RequestOption) ([]PromptRecord, error) {
+	var all []PromptRecord
+	for offset := 0; ; offset += registryPageSize {
+		var page struct {
+			Prompts []PromptRecord `json:"prompts"`
+			Total   int            `json:"total"`
+		}
+		path := "/api/prompts?limit=" + strconv.Itoa(registryPageSize) + "&offset=" + strconv.Itoa(offset)
+		if _, err := c.call(&apiRequest{op: "ListPromptRecords", method: http.MethodGet, path: path, opts: opts}, nil, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Prompts...)
+		if len(page.Prompts) < registryPageSize || len(all) >= page.Total {
+			return all, nil
+		}
+	}
+}
+
+// GetPromptRecord returns a prompt by registry ID with all its versions.
+func (c *Client) GetPromptRecord(id string, opts ...RequestOption) (*PromptRecord, error) {
+	var out PromptRecord
+	if _, err := c.call(&apiRequest{op: "GetPromptRecord", method: http.MethodGet, path: "/api/prompts/" + url.PathEscape(id), opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreatePrompt registers a new prompt (name, description, tags); add content with CreatePromptVersion.
+func (c *Client) CreatePrompt(rec PromptRecord, opts ...RequestOption) (*PromptRecord, error) {
+	if rec.Name == "" {
+		return nil, fmt.Errorf("sandarb: name is required for CreatePrompt")
+	}
+	body := map[string]interface{}{"name": rec.Name, "description": rec.Description, "tags": rec.Tags}
+	var out PromptRecord
+	if _, err := c.call(&apiRequest{op: "CreatePrompt", method: http.MethodPost, path: "/api/prompts", opts: opts}, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreatePromptVersion adds a new version of a prompt.
+func (c *Client) CreatePromptVersion(id string, v PromptVersion, ro RevisionOptions, opts ...RequestOption) (*PromptVersion, error) {
+	body := ro.body()
+	body["content"] = v.Content
+	if v.SystemPrompt != nil {
+		body["systemPrompt"] = *v.SystemPrompt
+	}
+	if v.Model != nil {
+		body["model"] = *v.Model
+	}
+	var out PromptVersion
+	path := "/api/prompts/" + url.PathEscape(id) + "/versions"
+	if _, err := c.call(&apiRequest{op: "CreatePromptVersion", method: http.MethodPost, path: path, opts: opts}, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
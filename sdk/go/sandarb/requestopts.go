@@ -0,0 +1,122 @@
+package sandarb
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestOption overrides client settings for a single call.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	ctx     context.Context
+	header  http.Header
+	query   url.Values
+	timeout time.Duration
+	traceID string
+	meta    *ResponseMeta
+
+	tokenBudget *TokenBudget // see WithTokenBudget
+
+	// notFoundOK marks lookups where a 404 means "none" rather than a failure; see optional
+	notFoundOK bool
+
+	// activity metadata; see WithAttachments and WithSubjectID
+	attachments []string
+	subjectID   string
+}
+
+// optional marks an internal lookup whose 404 the caller handles as "none", so it is not
+// reported to the error handler.
+func optional() RequestOption {
+	return func(o *requestOptions) { o.notFoundOK = true }
+}
+
+// WithHeader sets an extra HTTP header on the request (overriding client defaults).
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Set(key, value)
+	}
+}
+
+// WithQueryParam adds a query parameter to the request URL.
+func WithQueryParam(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		o.query.Add(key, value)
+	}
+}
+
+// WithRequestTimeout bounds the whole call, including failover attempts.
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) { o.timeout = d }
+}
+
+// WithTraceID sets the trace ID for the call instead of generating one.
+func WithTraceID(traceID string) RequestOption {
+	return func(o *requestOptions) { o.traceID = traceID }
+}
+
+// WithContext sets the context governing the call's cancellation and deadline.
+func WithContext(ctx context.Context) RequestOption {
+	return func(o *requestOptions) { o.ctx = ctx }
+}
+
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{}
+	for _, fn := range opts {
+		fn(o)
+	}
+	if o.ctx == nil {
+		o.ctx = context.Background()
+	}
+	return o
+}
+
+// newTraceID returns a random (v4) trace ID.
+func newTraceID() string {
+	return uuid.New().String()
+}
+
+// traceIDOr returns the WithTraceID override from opts, or fallback() if none was given.
+func traceIDOr(opts []RequestOption, fallback func() string) string {
+	if o := newRequestOptions(opts); o.traceID != "" {
+		return o.traceID
+	}
+	return fallback()
+}
+
+// withQuery appends extra query parameters to path (which may already have a query string).
+func withQuery(path string, q url.Values) string {
+	if len(q) == 0 {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + q.Encode()
+}
+
+// cancelOnClose releases a per-request context once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,65 @@
+package sandarb
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Role is a named set of permissions that can be assigned to principals (agents, service accounts, users).
+type Role struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleAssignment binds a role to a principal.
+type RoleAssignment struct {
+	Principal string `json:"principal"`
+	RoleID    string `json:"role_id"`
+}
+
+// PermissionDecision is the result of CheckPermission.
+type PermissionDecision struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+	// MatchedRole is the role that granted access, if any.
+	MatchedRole string `json:"matched_role,omitempty"`
+}
+
+// ListRoles returns the roles defined on the server.
+func (c *Client) ListRoles(opts ...RequestOption) ([]Role, error) {
+	var out []Role
+	if _, err := c.call(&apiRequest{op: "ListRoles", feature: FeatureRoles, method: http.MethodGet, path: "/api/roles", opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AssignRole grants roleID to principal (e.g. an agent ID or service account).
+func (c *Client) AssignRole(principal, roleID string, opts ...RequestOption) (*RoleAssignment, error) {
+	if principal == "" || roleID == "" {
+		return nil, fmt.Errorf("sandarb: principal and role id are required for AssignRole")
+	}
+	path := "/api/roles/" + url.PathEscape(roleID) + "/assignments"
+	var out RoleAssignment
+	if _, err := c.call(&apiRequest{op: "AssignRole", feature: FeatureRoles, method: http.MethodPost, path: path, opts: opts}, map[string]string{"principal": principal}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CheckPermission asks the server whether principal may perform action on resource
+// (e.g. "pull" on "prompt.kyc-playbook"), without attempting the operation.
+func (c *Client) CheckPermission(principal, action, resource string, opts ...RequestOption) (*PermissionDecision, error) {
+	if principal == "" || action == "" || resource == "" {
+		return nil, fmt.Errorf("sandarb: principal, action and resource are required for CheckPermission")
+	}
+	body := map[string]string{"principal": principal, "action": action, "resource": resource}
+	var out PermissionDecision
+	if _, err := c.call(&apiRequest{op: "CheckPermission", feature: FeatureRoles, method: http.MethodPost, path: "/api/permissions/check", opts: opts}, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
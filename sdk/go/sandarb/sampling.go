@@ -0,0 +1,97 @@
+package sandarb
+
+import (
+	"hash/fnv"
+	"math/rand"
+)
+
+// SampleRule overrides the default activity sampling rate for matching records.
+// Rules are evaluated in order; the first match wins.
+type SampleRule struct {
+	// AgentID restricts the rule to one agent; empty matches any agent.
+	AgentID string
+	// ErrorsOnly restricts the rule to records of failed calls.
+	ErrorsOnly bool
+	// Rate is the fraction of matching records to log, from 0 to 1.
+	Rate float64
+}
+
+// AlwaysLogErrors is a SampleRule that logs every failed call.
+func AlwaysLogErrors() SampleRule {
+	return SampleRule{ErrorsOnly: true, Rate: 1}
+}
+
+// SampleAgent is a SampleRule applying rate to one agent.
+func SampleAgent(agentID string, rate float64) SampleRule {
+	return SampleRule{AgentID: agentID, Rate: rate}
+}
+
+// sampler decides which activity records are logged.
+type sampler struct {
+	rate  float64
+	rules []SampleRule
+}
+
+// WithActivitySampling logs only a fraction of activity records: rate applies by default and
+// rules override it (e.g. WithActivitySampling(0.01, AlwaysLogErrors())). Decisions are made per
+// trace, so all records of a trace are kept or dropped together, and are recorded in metadata.
+func WithActivitySampling(rate float64, rules ...SampleRule) ClientOption {
+	return func(c *Client) {
+		c.sampler = &sampler{rate: rate, rules: append([]SampleRule(nil), rules...)}
+	}
+}
+
+// sample reports whether rec should be logged and, if so, annotates its metadata with the decision.
+func (s *sampler) sample(rec *ActivityRecord) bool {
+	rate, rule := s.rate, -1
+	failed := isFailedActivity(rec)
+	for i, r := range s.rules {
+		if r.AgentID != "" && r.AgentID != rec.AgentID {
+			continue
+		}
+		if r.ErrorsOnly && !failed {
+			continue
+		}
+		rate, rule = r.Rate, i
+		break
+	}
+	if !sampleDecision(rec.TraceID, rate) {
+		return false
+	}
+	if rec.Metadata == nil {
+		rec.Metadata = make(map[string]interface{})
+	}
+	decision := map[string]interface{}{"sampled": true, "rate": rate}
+	if rule >= 0 {
+		decision["rule"] = rule
+	}
+	rec.Metadata["sampling"] = decision
+	return true
+}
+
+// sampleDecision keeps a fraction rate of traces, deterministically by trace ID when present.
+func sampleDecision(traceID string, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	if traceID == "" {
+		return rand.Float64() < rate
+	}
+	h := fnv.New64a()
+	h.Write([]byte(traceID))
+	return float64(h.Sum64()%1_000_000)/1_000_000 < rate
+}
+
+// isFailedActivity reports whether rec describes a failed call (an "error" output or success=false metadata).
+func isFailedActivity(rec *ActivityRecord) bool {
+	if _, ok := rec.Outputs["error"]; ok {
+		return true
+	}
+	if ok, isBool := rec.Metadata["success"].(bool); isBool && !ok {
+		return true
+	}
+	return false
+}
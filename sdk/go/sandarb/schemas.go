@@ -0,0 +1,149 @@
+package sandarb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// latestSchemaTTL bounds how long GetContextAs trusts a cached "latest" schema lookup.
+const latestSchemaTTL = 5 * time.Minute
+
+// Schema is a registered JSON Schema describing the shape of a context's content.
+// Subject is the context name the schema applies to; versions increase on each registration.
+type Schema struct {
+	Subject   string          `json:"subject"`
+	Version   int             `json:"version"`
+	Schema    json.RawMessage `json:"schema"`
+	CreatedAt *time.Time      `json:"created_at,omitempty"`
+}
+
+type cachedSchema struct {
+	schema   *Schema // nil when no schema is registered
+	compiled *jsonSchema
+	expires  time.Time
+}
+
+// RegisterSchema publishes a new schema version for subject (a context name).
+func (c *Client) RegisterSchema(subject string, schema json.RawMessage, opts ...RequestOption) (*Schema, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("sandarb: subject is required for RegisterSchema")
+	}
+	if _, err := compileJSONSchema(schema); err != nil {
+		return nil, fmt.Errorf("sandarb: schema for %q: %w", subject, err)
+	}
+	body := map[string]interface{}{"subject": subject, "schema": schema}
+	var out Schema
+	if _, err := c.call(&apiRequest{op: "RegisterSchema", feature: FeatureSchemas, method: http.MethodPost, path: "/api/schemas", opts: opts}, body, &out); err != nil {
+		return nil, err
+	}
+	c.schemas.Delete(schemaCacheKey(subject, 0))
+	return &out, nil
+}
+
+// GetSchema returns version of subject's schema; version 0 returns the latest.
+func (c *Client) GetSchema(subject string, version int, opts ...RequestOption) (*Schema, error) {
+	v := "latest"
+	if version > 0 {
+		v = strconv.Itoa(version)
+	}
+	path := "/api/schemas/" + url.PathEscape(subject) + "/versions/" + v
+	var out Schema
+	if _, err := c.call(&apiRequest{op: "GetSchema", feature: FeatureSchemas, method: http.MethodGet, path: path, opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSchemas returns registered schemas, all versions of subject or every subject's latest if subject is empty.
+func (c *Client) ListSchemas(subject string, opts ...RequestOption) ([]Schema, error) {
+	path := "/api/schemas"
+	if subject != "" {
+		path += "?subject=" + url.QueryEscape(subject)
+	}
+	var out []Schema
+	if _, err := c.call(&apiRequest{op: "ListSchemas", feature: FeatureSchemas, method: http.MethodGet, path: path, opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetContextAs fetches a context, validates it against its registered schema (the version the
+// server reports for the content, else the latest; skipped if none is registered), and decodes
+// it into T. Validation failures are returned as *SchemaValidationError.
+func GetContextAs[T any](c *Client, ctxName, agentID string, opts ...RequestOption) (*T, *GetContextResult, error) {
+	res, err := c.GetContext(ctxName, agentID, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, compiled, err := c.schemaFor(ctxName, res.SchemaVersion, opts)
+	if err != nil {
+		return nil, res, err
+	}
+	if schema != nil {
+		if violations := compiled.validate(toJSONValue(res.Content)); len(violations) > 0 {
+			return nil, res, &SchemaValidationError{Subject: ctxName, Version: schema.Version, Violations: violations}
+		}
+	}
+	b, err := json.Marshal(res.Content)
+	if err != nil {
+		return nil, res, err
+	}
+	var out T
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, res, fmt.Errorf("sandarb: decode context %q: %w", ctxName, err)
+	}
+	return &out, res, nil
+}
+
+// schemaFor returns the cached schema for subject at version (0 = latest) and its compiled form,
+// or nil if none is registered. A schema the SDK cannot enforce is an error.
+func (c *Client) schemaFor(subject string, version int, opts []RequestOption) (*Schema, *jsonSchema, error) {
+	key := schemaCacheKey(subject, version)
+	if v, ok := c.schemas.Load(key); ok {
+		cs := v.(cachedSchema)
+		if cs.expires.IsZero() || time.Now().Before(cs.expires) {
+			return cs.schema, cs.compiled, nil
+		}
+	}
+	s, err := c.GetSchema(subject, version, append(append([]RequestOption(nil), opts...), optional())...)
+	var se *SandarbError
+	if errors.Is(err, ErrUnsupportedFeature) || (errors.As(err, &se) && se.StatusCode == http.StatusNotFound) {
+		s, err = nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	cs := cachedSchema{schema: s}
+	if s != nil {
+		if cs.compiled, err = compileJSONSchema(s.Schema); err != nil {
+			return nil, nil, fmt.Errorf("sandarb: schema %s v%d: %w", subject, s.Version, err)
+		}
+	}
+	if version == 0 || s == nil {
+		cs.expires = time.Now().Add(latestSchemaTTL)
+	}
+	c.schemas.Store(key, cs)
+	return s, cs.compiled, nil
+}
+
+func schemaCacheKey(subject string, version int) string {
+	return subject + "@" + strconv.Itoa(version)
+}
+
+// toJSONValue normalizes content so numbers and nested values have their decoded-JSON types.
+func toJSONValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package sandarb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SecretAction is what the secret scanner does when it finds a secret.
+type SecretAction string
+
+const (
+	// SecretBlock fails the call with a *SecretError; nothing is sent.
+	SecretBlock SecretAction = "block"
+	// SecretRedact replaces each secret with "[REDACTED:<kind>]" and sends the call.
+	SecretRedact SecretAction = "redact"
+	// SecretWarn reports findings and sends the call unchanged.
+	SecretWarn SecretAction = "warn"
+)
+
+// SecretPattern detects one kind of secret in string values.
+type SecretPattern struct {
+	Kind   string
+	Regexp *regexp.Regexp
+}
+
+// DefaultSecretPatterns detect common API keys, bearer tokens, JWTs, and PEM private keys.
+var DefaultSecretPatterns = []SecretPattern{
+	{"private_key", regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY(?: BLOCK)?-----.*?(?:-----END [A-Z ]*PRIVATE KEY(?: BLOCK)?-----|$)`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"stripe_key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"llm_api_key", regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{"bearer_token", regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]{20,}=*`)},
+}
+
+// SecretScanOptions configures WithSecretScanning.
+type SecretScanOptions struct {
+	Action SecretAction
+	// Patterns replaces DefaultSecretPatterns when non-nil.
+	Patterns []SecretPattern
+	// OnFinding is called with every call's findings, whatever the action. If nil, SecretWarn
+	// findings are written to the standard logger.
+	OnFinding func(op string, findings []SecretFinding)
+}
+
+// SecretFinding locates one detected secret. Path is dotted from the payload root (e.g.
+// "variables.user_note" or "inputs.messages[2].content"); the secret itself is never included.
+type SecretFinding struct {
+	Path string
+	Kind string
+}
+
+// ErrSecretDetected is matched (via errors.Is) by *SecretError.
+var ErrSecretDetected = errors.New("sandarb: secret detected")
+
+// SecretError is returned when SecretBlock stops a call.
+type SecretError struct {
+	Op       string
+	Findings []SecretFinding
+}
+
+func (e *SecretError) Error() string {
+	parts := make([]string, 0, len(e.Findings))
+	for _, f := range e.Findings {
+		parts = append(parts, f.Kind+" at "+f.Path)
+	}
+	return fmt.Sprintf("sandarb: %s blocked, secrets detected: %s", e.Op, strings.Join(parts, ", "))
+}
+
+// Is makes errors.Is(err, ErrSecretDetected) true.
+func (e *SecretError) Is(target error) bool { return target == ErrSecretDetected }
+
+// WithSecretScanning scans prompt variables (GetPrompt, PreviewPrompt) and activity payloads
+// (inputs, outputs, and metadata of LogActivity, Session.LogTurn, Wrap) for secrets before they
+// leave the process, and blocks, redacts, or warns according to opts.Action (default SecretBlock).
+func WithSecretScanning(opts SecretScanOptions) ClientOption {
+	if opts.Action == "" {
+		opts.Action = SecretBlock
+	}
+	if opts.Patterns == nil {
+		opts.Patterns = DefaultSecretPatterns
+	}
+	return func(c *Client) { c.secrets = &secretScanner{opts: opts} }
+}
+
+type secretScanner struct {
+	opts SecretScanOptions
+}
+
+// scan checks the named payloads of op. It returns the payloads to send (redacted copies under
+// SecretRedact) or a *SecretError under SecretBlock.
+func (s *secretScanner) scan(op string, payloads map[string]map[string]interface{}) (map[string]map[string]interface{}, error) {
+	var findings []SecretFinding
+	out := make(map[string]map[string]interface{}, len(payloads))
+	for root, m := range payloads {
+		out[root] = m
+		if m == nil {
+			continue
+		}
+		norm, ok := toJSONValue(m).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var found []SecretFinding
+		redacted := s.walk(norm, root, &found)
+		findings = append(findings, found...)
+		if len(found) > 0 && s.opts.Action == SecretRedact {
+			out[root] = redacted.(map[string]interface{})
+		}
+	}
+	if len(findings) == 0 {
+		return payloads, nil
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Path < findings[j].Path })
+	if s.opts.OnFinding != nil {
+		s.opts.OnFinding(op, findings)
+	} else if s.opts.Action == SecretWarn {
+		log.Printf("sandarb: %s: secrets detected (sent unchanged): %v", op, findings)
+	}
+	if s.opts.Action == SecretBlock {
+		return nil, &SecretError{Op: op, Findings: findings}
+	}
+	return out, nil
+}
+
+// walk records findings in string values under v and returns v with secrets redacted.
+func (s *secretScanner) walk(v interface{}, path string, found *[]SecretFinding) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, vv := range t {
+			t[k] = s.walk(vv, path+"."+k, found)
+		}
+	case []interface{}:
+		for i, vv := range t {
+			t[i] = s.walk(vv, fmt.Sprintf("%s[%d]", path, i), found)
+		}
+	case string:
+		for _, p := range s.opts.Patterns {
+			if p.Regexp.MatchString(t) {
+				*found = append(*found, SecretFinding{Path: path, Kind: p.Kind})
+				t = p.Regexp.ReplaceAllLiteralString(t, "[REDACTED:"+p.Kind+"]")
+			}
+		}
+		return t
+	}
+	return v
+}
+
+// scanVariables applies secret scanning, if configured, to prompt variables.
+func (c *Client) scanVariables(op string, variables map[string]interface{}) (map[string]interface{}, error) {
+	if c.secrets == nil || len(variables) == 0 {
+		return variables, nil
+	}
+	p, err := c.secrets.scan(op, map[string]map[string]interface{}{"variables": variables})
+	if err != nil {
+		return nil, err
+	}
+	return p["variables"], nil
+}
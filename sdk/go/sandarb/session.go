@@ -0,0 +1,89 @@
+package sandarb
+
+import "sync"
+
+// Session binds an agent ID, a root trace ID, and default metadata so call sites don't
+// thread them through every call. A Session is safe for concurrent use.
+type Session struct {
+	client  *Client
+	agentID string
+	traceID string
+
+	// propagated from incoming trace headers; see SessionFromTraceHeaders
+	parentID   string
+	notSampled bool
+	baggage    map[string]string
+
+	mu       sync.Mutex
+	metadata map[string]interface{}
+	turns    int
+}
+
+// NewSession starts a session for agentID with a fresh root trace ID.
+func (c *Client) NewSession(agentID string) *Session {
+	return c.NewSessionWithTrace(agentID, "")
+}
+
+// NewSessionWithTrace starts a session continuing traceID (a new one is generated if empty).
+func (c *Client) NewSessionWithTrace(agentID, traceID string) *Session {
+	if traceID == "" {
+		traceID = newTraceID()
+	}
+	return &Session{client: c, agentID: agentID, traceID: traceID, metadata: make(map[string]interface{})}
+}
+
+// Client returns the client the session was created from.
+func (s *Session) Client() *Client { return s.client }
+
+// AgentID returns the session's agent ID.
+func (s *Session) AgentID() string { return s.agentID }
+
+// TraceID returns the session's root trace ID.
+func (s *Session) TraceID() string { return s.traceID }
+
+// SetMetadata sets a default metadata key attached to every activity logged by the session.
+func (s *Session) SetMetadata(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metadata[key] = value
+}
+
+// Metadata returns a copy of the session's default metadata.
+func (s *Session) Metadata() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]interface{}, len(s.metadata))
+	for k, v := range s.metadata {
+		out[k] = v
+	}
+	return out
+}
+
+// GetContext fetches context by name for the session's agent under the session trace.
+func (s *Session) GetContext(ctxName string, opts ...RequestOption) (*GetContextResult, error) {
+	return s.client.getContext(ctxName, s.agentID, s.traceID, opts)
+}
+
+// GetPrompt fetches a compiled prompt for the session's agent under the session trace.
+func (s *Session) GetPrompt(promptName string, variables map[string]interface{}, opts ...RequestOption) (*GetPromptResult, error) {
+	return s.client.GetPrompt(promptName, variables, s.agentID, s.traceID, opts...)
+}
+
+// LogTurn logs one conversational turn with the session's default metadata and a turn counter.
+func (s *Session) LogTurn(inputs, outputs map[string]interface{}, opts ...RequestOption) error {
+	s.mu.Lock()
+	s.turns++
+	meta := make(map[string]interface{}, len(s.metadata)+1)
+	for k, v := range s.metadata {
+		meta[k] = v
+	}
+	meta["turn"] = s.turns
+	s.mu.Unlock()
+	return s.client.logActivity(&ActivityRecord{
+		AgentID:  s.agentID,
+		TraceID:  s.traceID,
+		Inputs:   inputs,
+		Outputs:  outputs,
+		Metadata: meta,
+	}, opts)
+}
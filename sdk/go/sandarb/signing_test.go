@@ -0,0 +1,124 @@
+package sandarb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("s3cret")
+
+func signedRequest(t *testing.T, body string, ts time.Time) *http.Request {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "/api/audit/activity?x=1", bytes.NewBufferString(body))
+	sec := strconv.FormatInt(ts.Unix(), 10)
+	r.Header.Set(timestampHeader, sec)
+	r.Header.Set(signatureHeader, signatureScheme+ComputeSignature(testSecret, r.Method, r.URL.RequestURI(), sec, []byte(body)))
+	return r
+}
+
+func TestVerifySignature(t *testing.T) {
+	r := signedRequest(t, `{"a":1}`, time.Now())
+	if err := VerifySignature(r, testSecret, 0); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if body, _ := io.ReadAll(r.Body); string(body) != `{"a":1}` {
+		t.Errorf("body after verification = %q", body)
+	}
+
+	tests := []struct {
+		name string
+		req  func() *http.Request
+	}{
+		{"tampered body", func() *http.Request {
+			r := signedRequest(t, `{"a":1}`, time.Now())
+			r.Body = io.NopCloser(bytes.NewBufferString(`{"a":2}`))
+			return r
+		}},
+		{"wrong secret", func() *http.Request {
+			r := signedRequest(t, `{}`, time.Now())
+			sec := r.Header.Get(timestampHeader)
+			r.Header.Set(signatureHeader, signatureScheme+ComputeSignature([]byte("other"), r.Method, r.URL.RequestURI(), sec, []byte(`{}`)))
+			return r
+		}},
+		{"stale timestamp", func() *http.Request { return signedRequest(t, `{}`, time.Now().Add(-10*time.Minute)) }},
+		{"missing signature", func() *http.Request {
+			r := signedRequest(t, `{}`, time.Now())
+			r.Header.Del(signatureHeader)
+			return r
+		}},
+	}
+	for _, tt := range tests {
+		err := VerifySignature(tt.req(), testSecret, 0)
+		if !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: error = %v, want ErrInvalidSignature", tt.name, err)
+		}
+	}
+}
+
+func TestVerifySignatureAcceptsRotatedKeys(t *testing.T) {
+	r := signedRequest(t, `{}`, time.Now())
+	r.Header.Set(signatureHeader, "v1=deadbeef, "+r.Header.Get(signatureHeader))
+	if err := VerifySignature(r, testSecret, 0); err != nil {
+		t.Errorf("signature list rejected: %v", err)
+	}
+}
+
+func TestClientSignsRequests(t *testing.T) {
+	var keyID string
+	srv := httptest.NewServer(VerifyingHandler(testSecret, 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyID = r.Header.Get(keyIDHeader)
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	})))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRequestSigning("k1", testSecret))
+	if err := c.LogActivity("agent", "trace", map[string]interface{}{"q": "hi"}, nil); err != nil {
+		t.Fatalf("signed request rejected: %v", err)
+	}
+	if keyID != "k1" {
+		t.Errorf("%s = %q, want k1", keyIDHeader, keyID)
+	}
+
+	bad := NewClient(WithBaseURL(srv.URL), WithRequestSigning("k1", []byte("wrong")))
+	var se *SandarbError
+	if err := bad.LogActivity("agent", "trace", nil, nil); !errors.As(err, &se) || se.StatusCode != http.StatusUnauthorized {
+		t.Errorf("request with wrong secret: error = %v, want 401", err)
+	}
+}
+
+func TestClientResignsAfterClockDrift(t *testing.T) {
+	serverNow := time.Now().Add(time.Hour)
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Date", serverNow.UTC().Format(http.TimeFormat))
+		sec, _ := strconv.ParseInt(r.Header.Get(timestampHeader), 10, 64)
+		if d := serverNow.Sub(time.Unix(sec, 0)); d > time.Minute || d < -time.Minute {
+			http.Error(w, "stale timestamp", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRequestSigning("k1", testSecret))
+	if err := c.LogActivity("agent", "trace", nil, nil); err != nil {
+		t.Fatalf("request not retried with corrected clock: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("server saw %d requests, want 2", n)
+	}
+	if err := c.LogActivity("agent", "trace2", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("server saw %d requests, want 3 once the offset is known", n)
+	}
+}
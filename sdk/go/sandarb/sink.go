@@ -0,0 +1,87 @@
+package sandarb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ActivitySink receives activity records from LogActivity (and Session.LogTurn, Wrap, ...) after
+// sampling and payload limits are applied. The sandarb/sinks package adapts Kafka and SQS clients.
+type ActivitySink interface {
+	WriteActivity(ctx context.Context, rec *ActivityRecord) error
+}
+
+// ActivitySinkFunc adapts a function to ActivitySink.
+type ActivitySinkFunc func(ctx context.Context, rec *ActivityRecord) error
+
+// WriteActivity implements ActivitySink.
+func (f ActivitySinkFunc) WriteActivity(ctx context.Context, rec *ActivityRecord) error {
+	return f(ctx, rec)
+}
+
+// SinkMode controls how a configured ActivitySink relates to the Sandarb audit API.
+type SinkMode int
+
+const (
+	// SinkRedirect sends activity only to the sink; the audit API (and spool) are bypassed.
+	SinkRedirect SinkMode = iota
+	// SinkDualWrite sends activity to the audit API (spooling on endpoint failures as usual) and to the sink.
+	SinkDualWrite
+)
+
+// SinkError wraps an error returned by the configured ActivitySink.
+type SinkError struct {
+	Err error
+}
+
+func (e *SinkError) Error() string { return "sandarb: activity sink: " + e.Err.Error() }
+
+// Unwrap returns the sink's error.
+func (e *SinkError) Unwrap() error { return e.Err }
+
+// WithActivitySink routes activity records to s, either instead of the audit API (SinkRedirect)
+// or in addition to it (SinkDualWrite). The call's context (WithContext) is passed to the sink.
+func WithActivitySink(s ActivitySink, mode SinkMode) ClientOption {
+	return func(c *Client) {
+		c.sink = s
+		c.sinkMode = mode
+	}
+}
+
+// writeSink delivers rec to the configured sink.
+func (c *Client) writeSink(rec *ActivityRecord, opts []RequestOption) error {
+	if err := c.sink.WriteActivity(newRequestOptions(opts).ctx, rec); err != nil {
+		return &SinkError{Err: err}
+	}
+	return nil
+}
+
+// deliverActivity sends rec to the audit API and/or the configured sink according to the sink mode.
+// Under dry-run the record is only captured: the sink and spool are left untouched.
+func (c *Client) deliverActivity(rec *ActivityRecord, opts []RequestOption) error {
+	if c.dryRun != nil {
+		return c.postActivity(rec, opts)
+	}
+	if c.sink != nil && c.sinkMode == SinkRedirect {
+		return c.writeSink(rec, opts)
+	}
+	err := c.postActivity(rec, opts)
+	if err == nil {
+		c.replaySpool()
+	} else if c.spool != nil && isEndpointFailure(err) {
+		if spoolErr := c.spool.Append(rec); spoolErr != nil {
+			err = fmt.Errorf("%w (spool: %v)", err, spoolErr)
+		} else {
+			err = nil
+		}
+	}
+	if c.sink != nil {
+		if sinkErr := c.writeSink(rec, opts); sinkErr != nil {
+			if err != nil {
+				return fmt.Errorf("%w (%v)", err, sinkErr)
+			}
+			return sinkErr
+		}
+	}
+	return err
+}
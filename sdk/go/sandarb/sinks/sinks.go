@@ -0,0 +1,104 @@
+// Package sinks adapts message queue clients to sandarb.ActivitySink, so activity can flow
+// through an organization's own event bus. The adapters depend only on small interfaces, which
+// any Kafka or SQS client library satisfies with a few lines of glue:
+//
+//	type kafkaGo struct{ w *kafka.Writer } // github.com/segmentio/kafka-go
+//
+//	func (k kafkaGo) Produce(ctx context.Context, m sinks.Message) error {
+//		hs := make([]kafka.Header, 0, len(m.Headers))
+//		for k, v := range m.Headers {
+//			hs = append(hs, kafka.Header{Key: k, Value: []byte(v)})
+//		}
+//		return k.w.WriteMessages(ctx, kafka.Message{Topic: m.Topic, Key: m.Key, Value: m.Value, Headers: hs})
+//	}
+//
+//	client := sandarb.NewClient(sandarb.WithActivitySink(sinks.NewKafkaSink(kafkaGo{w}, "sandarb.activity"), sandarb.SinkDualWrite))
+package sinks
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb"
+)
+
+// Message is an encoded activity record ready to publish.
+type Message struct {
+	// Topic is the Kafka topic (empty for SQS).
+	Topic string
+	// Key is the agent ID, so one agent's activity stays ordered within a partition or message group.
+	Key []byte
+	// Value is the JSON-encoded sandarb.ActivityRecord.
+	Value []byte
+	// Headers carry routing metadata: agent_id, trace_id, and content_type.
+	Headers map[string]string
+}
+
+// encode builds the Message for rec.
+func encode(topic string, rec *sandarb.ActivityRecord) (Message, error) {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Topic: topic,
+		Key:   []byte(rec.AgentID),
+		Value: b,
+		Headers: map[string]string{
+			"agent_id":     rec.AgentID,
+			"trace_id":     rec.TraceID,
+			"content_type": "application/json",
+		},
+	}, nil
+}
+
+// KafkaProducer publishes one message to Kafka, returning once it is acknowledged.
+type KafkaProducer interface {
+	Produce(ctx context.Context, m Message) error
+}
+
+// KafkaSink writes activity records to a Kafka topic, keyed by agent ID.
+type KafkaSink struct {
+	producer KafkaProducer
+	topic    string
+}
+
+// NewKafkaSink returns a sink publishing to topic through p.
+func NewKafkaSink(p KafkaProducer, topic string) *KafkaSink {
+	return &KafkaSink{producer: p, topic: topic}
+}
+
+// WriteActivity implements sandarb.ActivitySink.
+func (s *KafkaSink) WriteActivity(ctx context.Context, rec *sandarb.ActivityRecord) error {
+	m, err := encode(s.topic, rec)
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(ctx, m)
+}
+
+// SQSSender sends one message to an SQS queue. For FIFO queues, use Message.Key as the
+// message group ID; Message.Headers map to message attributes.
+type SQSSender interface {
+	SendMessage(ctx context.Context, queueURL string, m Message) error
+}
+
+// SQSSink writes activity records to an SQS queue.
+type SQSSink struct {
+	sender   SQSSender
+	queueURL string
+}
+
+// NewSQSSink returns a sink sending to queueURL through s.
+func NewSQSSink(s SQSSender, queueURL string) *SQSSink {
+	return &SQSSink{sender: s, queueURL: queueURL}
+}
+
+// WriteActivity implements sandarb.ActivitySink.
+func (s *SQSSink) WriteActivity(ctx context.Context, rec *sandarb.ActivityRecord) error {
+	m, err := encode("", rec)
+	if err != nil {
+		return err
+	}
+	return s.sender.SendMessage(ctx, s.queueURL, m)
+}
@@ -0,0 +1,304 @@
+package sandarb
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	spoolFileName       = "activity.wal"
+	spoolHeaderSize     = 8
+	defaultSpoolMaxSize = 64 << 20
+	maxSpoolFrameSize   = 16 << 20
+)
+
+// ErrSpoolFull is returned when spooling a record would exceed the spool's size cap.
+var ErrSpoolFull = errors.New("sandarb: activity spool is full")
+
+// SpoolOptions configures OpenSpool.
+type SpoolOptions struct {
+	// MaxBytes caps the spool file size (default 64 MiB). Records beyond it are rejected with ErrSpoolFull.
+	MaxBytes int64
+	// Sync fsyncs after every append for durability across power loss (slower).
+	Sync bool
+	// DeadLetter, if set, receives records removed by Replay because they were rejected
+	// permanently (e.g. a 4xx), along with the rejection.
+	DeadLetter func(rec *ActivityRecord, err error)
+}
+
+// Spool is an on-disk write-ahead log of activity records that could not be delivered.
+// Each frame is [length uint32][crc32 uint32][JSON record]; corrupt or truncated frames are
+// skipped on recovery. Use it with WithSpool.
+type Spool struct {
+	replayMu   sync.Mutex // serializes Replay
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	sync       bool
+	deadLetter func(*ActivityRecord, error)
+	f          *os.File
+	size       int64
+}
+
+// OpenSpool opens (or creates) a spool in dir.
+func OpenSpool(dir string, opts SpoolOptions) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultSpoolMaxSize
+	}
+	path := filepath.Join(dir, spoolFileName)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &Spool{path: path, maxBytes: opts.MaxBytes, sync: opts.Sync, deadLetter: opts.DeadLetter, f: f, size: st.Size()}, nil
+}
+
+// WithSpool persists activity records that fail with a transport error or 5xx to s; they are
+// replayed in the background after the next successful write. LogActivity returns nil once spooled.
+// Records the server rejects on replay are dropped (see SpoolOptions.DeadLetter).
+func WithSpool(s *Spool) ClientOption {
+	return func(c *Client) { c.spool = s }
+}
+
+// Append writes rec to the spool.
+func (s *Spool) Append(rec *ActivityRecord) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	frame := encodeSpoolFrame(payload)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return os.ErrClosed
+	}
+	if s.size+int64(len(frame)) > s.maxBytes {
+		return ErrSpoolFull
+	}
+	n, err := s.f.Write(frame)
+	s.size += int64(n)
+	if err != nil {
+		return err
+	}
+	if s.sync {
+		return s.f.Sync()
+	}
+	return nil
+}
+
+// Size returns the current spool file size in bytes.
+func (s *Spool) Size() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Len returns the number of records currently spooled.
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size == 0 {
+		return 0
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return 0
+	}
+	return len(decodeSpoolFrames(data))
+}
+
+// Replay sends spooled records in order, then rewrites the spool with those not delivered. A
+// retryable failure (a transport error or 5xx) stops the replay, leaving that record and the
+// rest for the next one; a permanent rejection (any other *SandarbError, e.g. a 4xx) removes
+// the record and passes it to SpoolOptions.DeadLetter, so one bad record cannot block the spool.
+// Records are sent without holding the spool lock, so Append is not blocked by a slow server.
+// It returns the number of records delivered and the failure that stopped the replay, if any.
+func (s *Spool) Replay(send func(*ActivityRecord) error) (int, error) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	s.mu.Lock()
+	if s.f == nil {
+		s.mu.Unlock()
+		return 0, os.ErrClosed
+	}
+	if s.size == 0 {
+		s.mu.Unlock()
+		return 0, nil
+	}
+	data, err := os.ReadFile(s.path)
+	s.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+	recs := decodeSpoolFrames(data)
+	sent, next := 0, 0
+	var sendErr error
+	for ; next < len(recs); next++ {
+		err := send(recs[next])
+		if err == nil {
+			sent++
+			continue
+		}
+		if isEndpointFailure(err) {
+			sendErr = err
+			break
+		}
+		if s.deadLetter != nil {
+			s.deadLetter(recs[next], err)
+		}
+	}
+	if next == 0 {
+		return 0, sendErr
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Keep records appended while sending; frames are only ever appended, so they follow data.
+	current, err := os.ReadFile(s.path)
+	if err != nil {
+		return sent, err
+	}
+	keep := recs[next:]
+	if len(current) > len(data) {
+		keep = append(keep[:len(keep):len(keep)], decodeSpoolFrames(current[len(data):])...)
+	}
+	if err := s.rewrite(keep); err != nil {
+		return sent, err
+	}
+	return sent, sendErr
+}
+
+// rewrite atomically replaces the spool file with recs, reopening it unless the spool was
+// closed. Caller holds s.mu.
+func (s *Spool) rewrite(recs []*ActivityRecord) error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	var size int64
+	for _, rec := range recs {
+		payload, err := json.Marshal(rec)
+		if err != nil {
+			continue
+		}
+		frame := encodeSpoolFrame(payload)
+		w.Write(frame)
+		size += int64(len(frame))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	open := s.f != nil
+	if open {
+		s.f.Close()
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	if !open {
+		s.size = size
+		return nil
+	}
+	s.f, err = os.OpenFile(s.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		s.f = nil
+		return err
+	}
+	s.size = size
+	return nil
+}
+
+// Close closes the spool file; spooled records remain on disk for the next OpenSpool.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
+
+func encodeSpoolFrame(payload []byte) []byte {
+	frame := make([]byte, spoolHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[spoolHeaderSize:], payload)
+	return frame
+}
+
+// decodeSpoolFrames returns every valid record in data. On a corrupt frame it resynchronizes
+// by scanning forward byte by byte for the next frame whose checksum and JSON are valid.
+func decodeSpoolFrames(data []byte) []*ActivityRecord {
+	var recs []*ActivityRecord
+	for off := 0; off+spoolHeaderSize <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[off : off+4]))
+		sum := binary.BigEndian.Uint32(data[off+4 : off+8])
+		end := off + spoolHeaderSize + n
+		if n == 0 || n > maxSpoolFrameSize || end > len(data) {
+			off++
+			continue
+		}
+		payload := data[off+spoolHeaderSize : end]
+		if crc32.ChecksumIEEE(payload) != sum {
+			off++
+			continue
+		}
+		var rec ActivityRecord
+		if err := json.Unmarshal(payload, &rec); err != nil {
+			off++
+			continue
+		}
+		recs = append(recs, &rec)
+		off = end
+	}
+	return recs
+}
+
+// replaySpool drains the spool in the background; at most one replay runs at a time.
+func (c *Client) replaySpool() {
+	if c.spool == nil || c.spool.Size() == 0 || !atomic.CompareAndSwapInt32(&c.replaying, 0, 1) {
+		return
+	}
+	started := c.goBackground(func(ctx context.Context) {
+		defer atomic.StoreInt32(&c.replaying, 0)
+		// Stop between records on shutdown rather than abandoning a send the server may have
+		// processed; Close replays what is left.
+		c.spool.Replay(func(rec *ActivityRecord) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return c.postActivity(rec, nil)
+		})
+	})
+	if !started {
+		atomic.StoreInt32(&c.replaying, 0)
+	}
+}
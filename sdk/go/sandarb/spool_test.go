@@ -0,0 +1,155 @@
+package sandarb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func openTestSpool(t *testing.T, opts SpoolOptions) *Spool {
+	t.Helper()
+	s, err := OpenSpool(t.TempDir(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func appendTraces(t *testing.T, s *Spool, traces ...string) {
+	t.Helper()
+	for _, tr := range traces {
+		if err := s.Append(&ActivityRecord{AgentID: "agent", TraceID: tr}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSpoolReplayDropsPermanentFailures(t *testing.T) {
+	var dead []string
+	s := openTestSpool(t, SpoolOptions{DeadLetter: func(rec *ActivityRecord, err error) { dead = append(dead, rec.TraceID) }})
+	appendTraces(t, s, "t1", "bad", "t2")
+
+	var got []string
+	sent, err := s.Replay(func(rec *ActivityRecord) error {
+		if rec.TraceID == "bad" {
+			return &SandarbError{Message: "invalid record", StatusCode: http.StatusUnprocessableEntity}
+		}
+		got = append(got, rec.TraceID)
+		return nil
+	})
+	if err != nil || sent != 2 {
+		t.Fatalf("Replay = %d, %v; want 2, nil", sent, err)
+	}
+	if len(got) != 2 || got[0] != "t1" || got[1] != "t2" {
+		t.Errorf("delivered %v, want [t1 t2]", got)
+	}
+	if len(dead) != 1 || dead[0] != "bad" {
+		t.Errorf("dead-lettered %v, want [bad]", dead)
+	}
+	if n := s.Len(); n != 0 {
+		t.Errorf("Len after replay = %d, want 0", n)
+	}
+}
+
+func TestSpoolReplayStopsAtRetryableFailure(t *testing.T) {
+	s := openTestSpool(t, SpoolOptions{})
+	appendTraces(t, s, "t1", "t2", "t3")
+
+	unavailable := &SandarbError{Message: "unavailable", StatusCode: http.StatusServiceUnavailable}
+	sent, err := s.Replay(func(rec *ActivityRecord) error {
+		if rec.TraceID == "t2" {
+			return unavailable
+		}
+		return nil
+	})
+	if sent != 1 || err != unavailable {
+		t.Fatalf("Replay = %d, %v; want 1, %v", sent, err, unavailable)
+	}
+	if n := s.Len(); n != 2 {
+		t.Errorf("Len after replay = %d, want 2", n)
+	}
+}
+
+func TestSpoolAppendDuringReplay(t *testing.T) {
+	s := openTestSpool(t, SpoolOptions{})
+	appendTraces(t, s, "t1", "t2")
+
+	var delivered []string
+	sent, err := s.Replay(func(rec *ActivityRecord) error {
+		if rec.TraceID == "t1" {
+			// Append takes the spool lock; it must not be held while sending.
+			appendTraces(t, s, "late")
+		}
+		delivered = append(delivered, rec.TraceID)
+		return nil
+	})
+	if err != nil || sent != 2 {
+		t.Fatalf("Replay = %d, %v; want 2, nil", sent, err)
+	}
+	if n := s.Len(); n != 1 {
+		t.Fatalf("Len after replay = %d, want the 1 record appended during it", n)
+	}
+	sent, err = s.Replay(func(rec *ActivityRecord) error {
+		delivered = append(delivered, rec.TraceID)
+		return nil
+	})
+	if err != nil || sent != 1 || delivered[2] != "late" {
+		t.Errorf("second Replay = %d, %v, delivered %v; want 1, nil, [t1 t2 late]", sent, err, delivered)
+	}
+}
+
+func TestClientReplaysSpoolConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	down := true
+	traces := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var rec ActivityRecord
+		json.NewDecoder(r.Body).Decode(&rec)
+		traces[rec.TraceID]++
+		w.Write([]byte(`{"success":true,"data":{}}`))
+	}))
+	defer srv.Close()
+
+	s := openTestSpool(t, SpoolOptions{})
+	c := NewClient(WithBaseURL(srv.URL), WithSpool(s))
+	for _, tr := range []string{"t1", "t2", "t3"} {
+		if err := c.LogActivity("agent", tr, nil, nil); err != nil {
+			t.Fatalf("LogActivity while down: %v", err)
+		}
+	}
+	mu.Lock()
+	down = false
+	mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, tr := range []string{"t4", "t5", "t6", "t7"} {
+		wg.Add(1)
+		go func(tr string) {
+			defer wg.Done()
+			if err := c.LogActivity("agent", tr, nil, nil); err != nil {
+				t.Errorf("LogActivity: %v", err)
+			}
+		}(tr)
+	}
+	wg.Wait()
+	if err := c.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, tr := range []string{"t1", "t2", "t3", "t4", "t5", "t6", "t7"} {
+		if traces[tr] != 1 {
+			t.Errorf("trace %s delivered %d times, want 1", tr, traces[tr])
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package stores provides sandarb.CacheStore implementations: FileStore for a local or shared
+// directory, and RedisStore over any Redis client. RedisStore depends only on a one-method
+// interface, which go-redis or redigo satisfies with a few lines of glue:
+//
+//	type goRedis struct{ c *redis.Client } // github.com/redis/go-redis/v9
+//
+//	func (r goRedis) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+//		v, err := r.c.Do(ctx, args...).Result()
+//		if err == redis.Nil {
+//			return nil, nil
+//		}
+//		return v, err
+//	}
+//
+//	client := sandarb.NewClient(sandarb.WithCacheStore(stores.NewRedisStore(goRedis{rdb}), sandarb.CacheStoreOptions{}))
+package stores
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileStore keeps each entry in its own file under a directory, e.g. /tmp on a serverless
+// instance or a volume shared by the processes on a host. Writes are atomic (write then rename),
+// so concurrent readers never see a partial entry.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore returns a store under dir, creating it if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// fileHeader is the size of the expiry (Unix nanoseconds) prefixed to each file.
+const fileHeader = 8
+
+func (s *FileStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".cache")
+}
+
+// Get implements sandarb.CacheStore. Expired entries are removed when read.
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	p := s.path(key)
+	b, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if len(b) < fileHeader {
+		os.Remove(p)
+		return nil, false, nil
+	}
+	if time.Now().UnixNano() > int64(binary.BigEndian.Uint64(b)) {
+		os.Remove(p)
+		return nil, false, nil
+	}
+	return b[fileHeader:], true, nil
+}
+
+// Set implements sandarb.CacheStore.
+func (s *FileStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	b := make([]byte, fileHeader+len(value))
+	binary.BigEndian.PutUint64(b, uint64(time.Now().Add(ttl).UnixNano()))
+	copy(b[fileHeader:], value)
+	f, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), s.path(key)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Prune removes expired entries and returns how many were removed. Long-lived hosts sharing a
+// directory can call it periodically; entries are otherwise only removed when read.
+func (s *FileStore) Prune() (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	now := time.Now().UnixNano()
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".cache") {
+			continue
+		}
+		p := filepath.Join(s.dir, e.Name())
+		f, err := os.Open(p)
+		if err != nil {
+			continue
+		}
+		var hdr [fileHeader]byte
+		_, err = f.Read(hdr[:])
+		f.Close()
+		if err != nil || now > int64(binary.BigEndian.Uint64(hdr[:])) {
+			if os.Remove(p) == nil {
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+// RedisDoer runs one Redis command, e.g. Do(ctx, "GET", key). A missing key must be returned
+// as a nil reply with a nil error.
+type RedisDoer interface {
+	Do(ctx context.Context, args ...interface{}) (interface{}, error)
+}
+
+// RedisStore keeps entries in Redis with a native expiry, so every instance of a service shares
+// one warm cache.
+type RedisStore struct {
+	redis RedisDoer
+}
+
+// NewRedisStore returns a store issuing GET and SET through r.
+func NewRedisStore(r RedisDoer) *RedisStore {
+	return &RedisStore{redis: r}
+}
+
+// Get implements sandarb.CacheStore.
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	v, err := s.redis.Do(ctx, "GET", key)
+	if err != nil || v == nil {
+		return nil, false, err
+	}
+	switch v := v.(type) {
+	case []byte:
+		return v, true, nil
+	case string:
+		return []byte(v), true, nil
+	}
+	return nil, false, fmt.Errorf("sandarb: unexpected redis reply %T for GET", v)
+}
+
+// Set implements sandarb.CacheStore.
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	ms := ttl.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	_, err := s.redis.Do(ctx, "SET", key, value, "PX", ms)
+	return err
+}
@@ -0,0 +1,150 @@
+package stores
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sandarb-ai/sandarb.ai/sdk/go/sandarb"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(filepath.Join(t.TempDir(), "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := s.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("Get(missing) = %v, %v; want a miss", ok, err)
+	}
+	if err := s.Set(ctx, "k", []byte("value"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, err := s.Get(ctx, "k"); !ok || err != nil || string(v) != "value" {
+		t.Errorf("Get(k) = %q, %v, %v", v, ok, err)
+	}
+	if err := s.Set(ctx, "k", []byte("replaced"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if v, _, _ := s.Get(ctx, "k"); string(v) != "replaced" {
+		t.Errorf("Get(k) after overwrite = %q", v)
+	}
+
+	if err := s.Set(ctx, "gone", []byte("x"), -time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.Get(ctx, "gone"); ok {
+		t.Error("expired entry returned")
+	}
+	if _, err := os.Stat(s.path("gone")); !os.IsNotExist(err) {
+		t.Errorf("expired entry not removed on read: %v", err)
+	}
+}
+
+func TestFileStorePrune(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set(ctx, "live", []byte("x"), time.Minute)
+	s.Set(ctx, "old1", []byte("x"), -time.Second)
+	s.Set(ctx, "old2", []byte("x"), -time.Second)
+	if n, err := s.Prune(); n != 2 || err != nil {
+		t.Errorf("Prune = %d, %v; want 2, nil", n, err)
+	}
+	if _, ok, _ := s.Get(ctx, "live"); !ok {
+		t.Error("live entry pruned")
+	}
+}
+
+// fakeRedis is a RedisDoer over a map that records each command.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]interface{}
+	cmds [][]interface{}
+}
+
+func (r *fakeRedis) Do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cmds = append(r.cmds, args)
+	key := args[1].(string)
+	switch args[0] {
+	case "GET":
+		return r.data[key], nil
+	case "SET":
+		r.data[key] = args[2]
+		return "OK", nil
+	}
+	return nil, nil
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	r := &fakeRedis{data: map[string]interface{}{}}
+	s := NewRedisStore(r)
+	if _, ok, err := s.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("Get(missing) = %v, %v; want a miss", ok, err)
+	}
+	if err := s.Set(ctx, "k", []byte("value"), 1500*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if set := r.cmds[1]; set[3] != "PX" || set[4] != int64(1500) {
+		t.Errorf("SET command = %v, want a PX 1500 expiry", set)
+	}
+	if v, ok, err := s.Get(ctx, "k"); !ok || err != nil || string(v) != "value" {
+		t.Errorf("Get(k) = %q, %v, %v", v, ok, err)
+	}
+	r.data["s"] = "string reply"
+	if v, ok, _ := s.Get(ctx, "s"); !ok || string(v) != "string reply" {
+		t.Errorf("Get(s) = %q, %v", v, ok)
+	}
+	r.data["n"] = int64(1)
+	if _, ok, err := s.Get(ctx, "n"); ok || err == nil || !strings.HasPrefix(err.Error(), "sandarb: ") {
+		t.Errorf("Get(n) = %v, %v; want an unexpected reply error", ok, err)
+	}
+}
+
+func TestClientsShareFileStore(t *testing.T) {
+	var pulls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/prompts/pull" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&pulls, 1)
+		w.Write([]byte(`{"success":true,"data":{"content":"Hello Ada","version":3}}`))
+	}))
+	defer srv.Close()
+
+	s, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	newClient := func() *sandarb.Client {
+		return sandarb.NewClient(sandarb.WithBaseURL(srv.URL), sandarb.WithAgentID("agent"),
+			sandarb.WithoutVariableValidation(), sandarb.WithCacheStore(s, sandarb.CacheStoreOptions{}))
+	}
+	vars := map[string]interface{}{"name": "Ada"}
+	if _, err := newClient().GetPrompt("greet", vars, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	r, err := newClient().GetPrompt("greet", vars, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Cached || r.Content != "Hello Ada" || r.Version != 3 {
+		t.Errorf("second client's result = %+v, want a cache hit", r)
+	}
+	if n := atomic.LoadInt32(&pulls); n != 1 {
+		t.Errorf("server saw %d pulls, want 1", n)
+	}
+}
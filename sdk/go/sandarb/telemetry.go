@@ -0,0 +1,46 @@
+package sandarb
+
+import (
+	"runtime"
+	"strings"
+)
+
+// Version is the SDK version reported in the User-Agent and X-Sandarb-SDK headers.
+const Version = "0.1.0"
+
+const sdkHeader = "X-Sandarb-SDK"
+
+// defaultUserAgent matches the other SDKs' "sandarb-<lang>-sdk/<version>" form.
+var defaultUserAgent = "sandarb-go-sdk/" + Version
+
+// sdkInfo is the X-Sandarb-SDK value: SDK language and version, Go version, and platform.
+var sdkInfo = "lang=go; version=" + Version + "; runtime=" + runtime.Version() + "; os=" + runtime.GOOS + "; arch=" + runtime.GOARCH
+
+// WithUserAgent prefixes the User-Agent with the application's own product token, e.g.
+// "fraud-screener/2.3", giving "fraud-screener/2.3 sandarb-go-sdk/0.1.0".
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) { c.userAgent = strings.TrimSpace(ua) }
+}
+
+// WithoutTelemetryHeaders stops the client from identifying its SDK version, Go version, and
+// platform: X-Sandarb-SDK is not sent and the User-Agent is only the WithUserAgent value, if any.
+// NewClient also honours SANDARB_DISABLE_TELEMETRY=1.
+func WithoutTelemetryHeaders() ClientOption {
+	return func(c *Client) { c.noTelemetry = true }
+}
+
+// setTelemetryHeaders adds User-Agent and X-Sandarb-SDK to h.
+func (c *Client) setTelemetryHeaders(h map[string]string) {
+	if c.noTelemetry {
+		if c.userAgent != "" {
+			h["User-Agent"] = c.userAgent
+		}
+		return
+	}
+	ua := defaultUserAgent
+	if c.userAgent != "" {
+		ua = c.userAgent + " " + ua
+	}
+	h["User-Agent"] = ua
+	h[sdkHeader] = sdkInfo
+}
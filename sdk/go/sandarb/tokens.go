@@ -0,0 +1,334 @@
+package sandarb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer counts tokens exactly for a model, e.g. an adapter over a tiktoken port:
+//
+//	type tiktokenCounter struct{}
+//
+//	func (tiktokenCounter) CountTokens(text, model string) (int, error) {
+//		enc, err := tiktoken.EncodingForModel(model)
+//		if err != nil {
+//			return 0, err
+//		}
+//		return len(enc.Encode(text, nil, nil)), nil
+//	}
+type Tokenizer interface {
+	CountTokens(text, model string) (int, error)
+}
+
+// WithTokenizer sets the tokenizer used by Client.CountTokens and token budget checks, in place of
+// DefaultTokenizer. Without either, or when it fails for a model, counts fall back to EstimateTokens.
+func WithTokenizer(t Tokenizer) ClientOption {
+	return func(c *Client) { c.tokenizer = t }
+}
+
+// DefaultTokenizer is used by TokenCount and by clients without WithTokenizer. The SDK ships no BPE
+// vocabularies, so tiktoken-compatible counts need it set (once, at startup) to an adapter over a
+// tiktoken port like the one shown on Tokenizer; while it is nil, counts are EstimateTokens.
+var DefaultTokenizer Tokenizer
+
+// TokenCount counts content's tokens for model with DefaultTokenizer. Without one, or when it
+// fails for model, the result is the EstimateTokens heuristic.
+func TokenCount(content, model string) int {
+	return countTokens(DefaultTokenizer, content, model)
+}
+
+func countTokens(t Tokenizer, content, model string) int {
+	if t != nil {
+		if n, err := t.CountTokens(content, model); err == nil {
+			return n
+		}
+	}
+	return EstimateTokens(content)
+}
+
+// WithModelWindows adds or overrides context window sizes (in tokens) by model name prefix,
+// on top of DefaultModelWindows.
+func WithModelWindows(windows map[string]int) ClientOption {
+	return func(c *Client) {
+		if c.modelWindows == nil {
+			c.modelWindows = make(map[string]int, len(windows))
+		}
+		for k, v := range windows {
+			c.modelWindows[k] = v
+		}
+	}
+}
+
+// DefaultModelWindows maps model name prefixes to context window sizes in tokens. The longest
+// matching prefix wins, so "gpt-4o" takes precedence over "gpt-4".
+var DefaultModelWindows = map[string]int{
+	"gpt-4.1":            1047576,
+	"gpt-4o":             128000,
+	"gpt-4-turbo":        128000,
+	"gpt-4-32k":          32768,
+	"gpt-4":              8192,
+	"gpt-3.5-turbo":      16385,
+	"o1":                 200000,
+	"o3":                 200000,
+	"o4-mini":            200000,
+	"claude":             200000,
+	"gemini-1.5-pro":     2097152,
+	"gemini-1.5-flash":   1048576,
+	"gemini-2":           1048576,
+	"llama-3.1":          131072,
+	"llama-3":            8192,
+	"mistral-large":      131072,
+	"command-r":          131072,
+	"text-embedding-3":   8191,
+	"text-embedding-ada": 8191,
+}
+
+// EstimateTokens is a model-independent heuristic for the number of tokens content encodes to.
+// It splits text the way tiktoken's cl100k/o200k pre-tokenizer does (words with a leading space,
+// digit groups of up to three, punctuation runs, newlines) and guesses the BPE merges per piece
+// without a vocabulary. On everyday English sentences it matches both encodings; words the
+// vocabulary splits are undercounted ("tiktoken" is three tokens, estimated as one), and for other
+// encodings, code or non-Latin scripts it can be well off. Use a Tokenizer for exact counts.
+func EstimateTokens(content string) int {
+	n := 0
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		switch {
+		case r == '\'' && i+1 < len(content) && contractionLen(content[i:]) > 0:
+			i += contractionLen(content[i:])
+			n++
+		case unicode.IsLetter(r) || (!isSpaceOrAlnum(r) && nextIsLetter(content[i+size:])):
+			j := i + size
+			letters := 0
+			if unicode.IsLetter(r) {
+				letters = 1
+			}
+			for j < len(content) {
+				r2, s2 := utf8.DecodeRuneInString(content[j:])
+				if !unicode.IsLetter(r2) {
+					break
+				}
+				letters++
+				j += s2
+			}
+			n += wordTokens(content[i:j], letters)
+			i = j
+		case unicode.IsDigit(r):
+			j, digits := i, 0
+			for j < len(content) {
+				r2, s2 := utf8.DecodeRuneInString(content[j:])
+				if !unicode.IsDigit(r2) {
+					break
+				}
+				digits++
+				j += s2
+			}
+			n += (digits + 2) / 3
+			i = j
+		case r == '\n' || r == '\r':
+			for i < len(content) && (content[i] == '\n' || content[i] == '\r') {
+				i++
+			}
+			n++
+		case unicode.IsSpace(r):
+			j := i + size
+			for j < len(content) && (content[j] == ' ' || content[j] == '\t') {
+				j++
+			}
+			// A single space merges into the following word or punctuation, but not a number;
+			// longer or trailing runs are a token.
+			if j-i > 1 || j == len(content) || content[j] == '\n' || content[j] == '\r' || isDigitAt(content[j:]) {
+				n++
+			}
+			i = j
+		default:
+			j, runes := i+size, 1
+			for j < len(content) {
+				r2, s2 := utf8.DecodeRuneInString(content[j:])
+				if isSpaceOrAlnum(r2) {
+					break
+				}
+				runes++
+				j += s2
+			}
+			n += (runes + 1) / 2
+			i = j
+		}
+	}
+	return n
+}
+
+// wordTokens estimates BPE tokens for a letter run (with any leading punctuation or space).
+// Common English words are one token; longer ones split every ~6 characters. Scripts without
+// Latin-style merges (e.g. CJK) cost about one token per character.
+func wordTokens(piece string, letters int) int {
+	nonASCII := 0
+	for _, r := range piece {
+		if r >= utf8.RuneSelf {
+			nonASCII++
+		}
+	}
+	if nonASCII*2 > letters {
+		return letters
+	}
+	if letters <= 6 {
+		return 1
+	}
+	return 1 + (letters-3)/6
+}
+
+func contractionLen(s string) int {
+	lower := strings.ToLower(s)
+	for _, c := range []string{"'ll", "'re", "'ve", "'s", "'t", "'m", "'d"} {
+		if strings.HasPrefix(lower, c) {
+			return len(c)
+		}
+	}
+	return 0
+}
+
+func isSpaceOrAlnum(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isDigitAt(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsDigit(r)
+}
+
+func nextIsLetter(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r)
+}
+
+// CountTokens counts content's tokens for model with the client's Tokenizer (see WithTokenizer),
+// else DefaultTokenizer. Without either, or when it fails for model, the result is the
+// EstimateTokens heuristic.
+func (c *Client) CountTokens(content, model string) int {
+	t := c.tokenizer
+	if t == nil {
+		t = DefaultTokenizer
+	}
+	return countTokens(t, content, model)
+}
+
+// WithTokenBudgetHandler calls fn when a GetPrompt token budget (see WithTokenBudget) is exceeded
+// and the budget has no OnExceeded of its own. fn runs synchronously on the calling goroutine.
+func WithTokenBudgetHandler(fn func(*TokenBudgetError)) ClientOption {
+	return func(c *Client) { c.onTokenBudget = fn }
+}
+
+// ModelWindow returns the context window size for model in tokens, or 0 if unknown.
+func (c *Client) ModelWindow(model string) int {
+	if n, ok := longestPrefix(c.modelWindows, model); ok {
+		return n
+	}
+	n, _ := longestPrefix(DefaultModelWindows, model)
+	return n
+}
+
+func longestPrefix(m map[string]int, model string) (int, bool) {
+	best, n := -1, 0
+	model = strings.ToLower(model)
+	for prefix, v := range m {
+		if strings.HasPrefix(model, strings.ToLower(prefix)) && len(prefix) > best {
+			best, n = len(prefix), v
+		}
+	}
+	return n, best >= 0
+}
+
+// BudgetAction is what a token budget check does when the window would be exceeded.
+type BudgetAction string
+
+const (
+	BudgetWarn  BudgetAction = "warn"
+	BudgetError BudgetAction = "error"
+)
+
+// TokenBudget configures WithTokenBudget.
+type TokenBudget struct {
+	// Model is the target model; empty uses the prompt's model.
+	Model string
+	// ContextTokens is the declared budget for context injected alongside the prompt.
+	ContextTokens int
+	// OutputTokens is reserved for the model's response.
+	OutputTokens int
+	// Action defaults to BudgetWarn.
+	Action BudgetAction
+	// OnExceeded is called when the budget is exceeded, whatever the action. If nil, the client's
+	// WithTokenBudgetHandler is called instead; with neither, a BudgetWarn overrun is visible only
+	// in GetPromptResult.Tokens.
+	OnExceeded func(*TokenBudgetError)
+}
+
+// TokenUsage is the token accounting of a compiled prompt against its target model's window.
+type TokenUsage struct {
+	Model        string `json:"model,omitempty"`
+	Prompt       int    `json:"prompt"`
+	SystemPrompt int    `json:"system_prompt,omitempty"`
+	Context      int    `json:"context,omitempty"`
+	Output       int    `json:"output,omitempty"`
+	// Total is the sum of the above.
+	Total int `json:"total"`
+	// Window is the model's context window (0 when the model is unknown; no check is made).
+	Window int `json:"window,omitempty"`
+}
+
+// ErrTokenBudgetExceeded is matched (via errors.Is) by *TokenBudgetError.
+var ErrTokenBudgetExceeded = errors.New("sandarb: token budget exceeded")
+
+// TokenBudgetError reports a prompt plus declared budgets that exceed the model's window.
+type TokenBudgetError struct {
+	Prompt string
+	Usage  TokenUsage
+}
+
+func (e *TokenBudgetError) Error() string {
+	return fmt.Sprintf("sandarb: prompt %q needs %d tokens (prompt %d, system %d, context %d, output %d) but %s has a %d-token window",
+		e.Prompt, e.Usage.Total, e.Usage.Prompt, e.Usage.SystemPrompt, e.Usage.Context, e.Usage.Output, e.Usage.Model, e.Usage.Window)
+}
+
+// Is makes errors.Is(err, ErrTokenBudgetExceeded) true.
+func (e *TokenBudgetError) Is(target error) bool { return target == ErrTokenBudgetExceeded }
+
+// WithTokenBudget makes GetPrompt count the compiled prompt's tokens (GetPromptResult.Tokens) and
+// check them, plus the declared context and output budgets, against the model's window.
+// TokenBudget{} counts and warns on prompts that alone exceed the window.
+func WithTokenBudget(b TokenBudget) RequestOption {
+	return func(o *requestOptions) { o.tokenBudget = &b }
+}
+
+// checkTokenBudget fills r.Tokens when a budget was requested and enforces it.
+func (c *Client) checkTokenBudget(name string, r *GetPromptResult, opts []RequestOption) (*GetPromptResult, error) {
+	b := newRequestOptions(opts).tokenBudget
+	if b == nil {
+		return r, nil
+	}
+	model := b.Model
+	if model == "" && r.Model != nil {
+		model = *r.Model
+	}
+	u := &TokenUsage{Model: model, Prompt: c.CountTokens(r.Content, model), Context: b.ContextTokens, Output: b.OutputTokens, Window: c.ModelWindow(model)}
+	if r.SystemPrompt != nil {
+		u.SystemPrompt = c.CountTokens(*r.SystemPrompt, model)
+	}
+	u.Total = u.Prompt + u.SystemPrompt + u.Context + u.Output
+	r.Tokens = u
+	if u.Window == 0 || u.Total <= u.Window {
+		return r, nil
+	}
+	err := &TokenBudgetError{Prompt: name, Usage: *u}
+	if b.OnExceeded != nil {
+		b.OnExceeded(err)
+	} else if c.onTokenBudget != nil {
+		c.onTokenBudget(err)
+	}
+	if b.Action == BudgetError {
+		return nil, err
+	}
+	return r, nil
+}
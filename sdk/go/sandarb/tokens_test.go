@@ -0,0 +1,69 @@
+package sandarb
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEstimateTokensAgainstTiktoken(t *testing.T) {
+	// Counts from tiktoken's cl100k_base and o200k_base encodings (0: not checked).
+	tests := []struct {
+		text           string
+		cl100k, o200k  int
+		undercountRare bool
+	}{
+		{text: "hello world", cl100k: 2, o200k: 2},
+		{text: "Hello, world!", cl100k: 4, o200k: 4},
+		{text: "The quick brown fox jumps over the lazy dog.", cl100k: 10, o200k: 10},
+		{text: "2 + 2 = 4", cl100k: 7, o200k: 7},
+		{text: "I can't believe it's not butter.", cl100k: 9},
+		// The vocabulary splits these words into several pieces the heuristic can't see.
+		{text: "tiktoken is great!", cl100k: 6, undercountRare: true},
+		{text: "antidisestablishmentarianism", cl100k: 6, undercountRare: true},
+	}
+	for _, tt := range tests {
+		got := EstimateTokens(tt.text)
+		for _, want := range []int{tt.cl100k, tt.o200k} {
+			switch {
+			case want == 0:
+			case tt.undercountRare && (got > want || got == 0):
+				t.Errorf("EstimateTokens(%q) = %d, want at most %d", tt.text, got, want)
+			case !tt.undercountRare && got != want:
+				t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, want)
+			}
+		}
+	}
+}
+
+type fakeTokenizer map[string]int
+
+func (f fakeTokenizer) CountTokens(text, model string) (int, error) {
+	if n, ok := f[model]; ok {
+		return n, nil
+	}
+	return 0, errors.New("unknown model")
+}
+
+func TestTokenCount(t *testing.T) {
+	defer func(t Tokenizer) { DefaultTokenizer = t }(DefaultTokenizer)
+	DefaultTokenizer = nil
+	if got := TokenCount("hello world", "gpt-4o"); got != 2 {
+		t.Errorf("TokenCount without a tokenizer = %d, want the estimate 2", got)
+	}
+
+	DefaultTokenizer = fakeTokenizer{"gpt-4o": 42}
+	if got := TokenCount("hello world", "gpt-4o"); got != 42 {
+		t.Errorf("TokenCount = %d, want DefaultTokenizer's 42", got)
+	}
+	if got := TokenCount("hello world", "llama-3"); got != 2 {
+		t.Errorf("TokenCount for a model the tokenizer rejects = %d, want the estimate 2", got)
+	}
+
+	c := NewClient(WithTokenizer(fakeTokenizer{"gpt-4o": 7}))
+	if got := c.CountTokens("hello world", "gpt-4o"); got != 7 {
+		t.Errorf("Client.CountTokens = %d, want WithTokenizer's 7", got)
+	}
+	if got := NewClient().CountTokens("hello world", "gpt-4o"); got != 42 {
+		t.Errorf("Client.CountTokens without WithTokenizer = %d, want DefaultTokenizer's 42", got)
+	}
+}
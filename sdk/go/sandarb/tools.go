@@ -0,0 +1,100 @@
+package sandarb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ToolSpec describes a tool (function) an agent wants to call.
+type ToolSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Parameters is the JSON Schema of the tool's arguments, as sent to the model.
+	Parameters json.RawMessage `json:"parameters,omitempty"`
+}
+
+// RegisteredTool is a tool registered for an agent, with its governance state.
+type RegisteredTool struct {
+	ToolSpec
+	ID           string        `json:"id"`
+	AgentID      string        `json:"agent_id"`
+	State        ApprovalState `json:"state"`
+	RegisteredAt *time.Time    `json:"registered_at,omitempty"`
+	DecidedBy    string        `json:"decided_by,omitempty"`
+}
+
+// RegisterTool registers a tool for an agent. New tools start pending and become callable once
+// approved on the server.
+func (c *Client) RegisterTool(agentID string, spec ToolSpec, opts ...RequestOption) (*RegisteredTool, error) {
+	if agentID == "" {
+		agentID = c.AgentID
+	}
+	if agentID == "" || spec.Name == "" {
+		return nil, fmt.Errorf("sandarb: agent_id and tool name are required for RegisterTool")
+	}
+	var out RegisteredTool
+	path := "/api/agents/" + url.PathEscape(agentID) + "/tools"
+	if _, err := c.call(&apiRequest{op: "RegisterTool", feature: FeatureTools, method: http.MethodPost, path: path, agentID: agentID, opts: opts}, spec, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListApprovedTools returns the tools the agent is currently allowed to call.
+func (c *Client) ListApprovedTools(agentID string, opts ...RequestOption) ([]RegisteredTool, error) {
+	if agentID == "" {
+		agentID = c.AgentID
+	}
+	if agentID == "" {
+		return nil, fmt.Errorf("sandarb: agent_id is required for ListApprovedTools (or set SANDARB_AGENT_ID)")
+	}
+	var out []RegisteredTool
+	path := "/api/agents/" + url.PathEscape(agentID) + "/tools?state=" + string(ApprovalApproved)
+	if _, err := c.call(&apiRequest{op: "ListApprovedTools", feature: FeatureTools, method: http.MethodGet, path: path, agentID: agentID, opts: opts}, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FilterOpenAITools filters an OpenAI tools array ([{"type":"function","function":{"name":...}}],
+// or the legacy functions array [{"name":...}]) down to entries whose name is in approved.
+// It returns the filtered JSON array and the names that were removed, in order.
+func FilterOpenAITools(tools json.RawMessage, approved []RegisteredTool) (json.RawMessage, []string, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(tools, &entries); err != nil {
+		return nil, nil, fmt.Errorf("sandarb: tools must be a JSON array: %w", err)
+	}
+	allowed := make(map[string]bool, len(approved))
+	for _, t := range approved {
+		if t.State == "" || t.State == ApprovalApproved {
+			allowed[t.Name] = true
+		}
+	}
+	kept := make([]json.RawMessage, 0, len(entries))
+	var removed []string
+	for i, e := range entries {
+		var entry struct {
+			Name     string `json:"name"`
+			Function struct {
+				Name string `json:"name"`
+			} `json:"function"`
+		}
+		if err := json.Unmarshal(e, &entry); err != nil {
+			return nil, nil, fmt.Errorf("sandarb: tools[%d]: %w", i, err)
+		}
+		name := entry.Function.Name
+		if name == "" {
+			name = entry.Name
+		}
+		if allowed[name] {
+			kept = append(kept, e)
+		} else {
+			removed = append(removed, name)
+		}
+	}
+	out, err := json.Marshal(kept)
+	return out, removed, err
+}
@@ -0,0 +1,190 @@
+package sandarb
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// W3C Trace Context headers. Sandarb state travels in baggage under these language-neutral
+// keys, so any service that honours them can continue the session:
+//
+//	sandarb.trace_id        the Sandarb trace ID, when it isn't the traceparent trace ID as a UUID
+//	sandarb.agent_id        the session's agent ID
+//	sandarb.session.<key>   session metadata (string, number, and bool values)
+const (
+	TraceparentHeader = "traceparent"
+	BaggageHeader     = "baggage"
+
+	baggageTraceID     = "sandarb.trace_id"
+	baggageAgentID     = "sandarb.agent_id"
+	baggageSessionMeta = "sandarb.session."
+)
+
+// TraceContext is trace and session state carried across services in W3C headers.
+type TraceContext struct {
+	// TraceID is the Sandarb trace ID (a UUID unless another SDK chose otherwise).
+	TraceID string
+	// ParentID is the 16-hex-digit span ID of the caller, from traceparent.
+	ParentID string
+	Sampled  bool
+	AgentID  string
+	// Metadata is the session metadata carried in baggage.
+	Metadata map[string]string
+	// Baggage holds other baggage members, which are propagated unchanged.
+	Baggage map[string]string
+}
+
+// TraceContext returns the session's state for propagation to another service.
+func (s *Session) TraceContext() *TraceContext {
+	tc := &TraceContext{TraceID: s.traceID, ParentID: s.parentID, Sampled: !s.notSampled, AgentID: s.agentID, Metadata: make(map[string]string)}
+	for k, v := range s.Metadata() {
+		switch v.(type) {
+		case string, bool, int, int32, int64, float32, float64:
+			tc.Metadata[k] = fmt.Sprint(v)
+		}
+	}
+	if len(s.baggage) > 0 {
+		tc.Baggage = make(map[string]string, len(s.baggage))
+		for k, v := range s.baggage {
+			tc.Baggage[k] = v
+		}
+	}
+	return tc
+}
+
+// InjectTraceHeaders sets traceparent and baggage on h so a downstream service (in any Sandarb
+// SDK) can continue the session. Each call starts a new child span ID.
+func (s *Session) InjectTraceHeaders(h http.Header) {
+	s.TraceContext().Inject(h)
+}
+
+// Inject sets traceparent and baggage headers for tc on h.
+func (tc *TraceContext) Inject(h http.Header) {
+	traceHex := strings.ReplaceAll(tc.TraceID, "-", "")
+	carryID := false
+	if !isHex(traceHex, 32) || traceHex == strings.Repeat("0", 32) {
+		// Not representable as a W3C trace ID: derive a stable one and carry the original in baggage.
+		sum := sha256.Sum256([]byte(tc.TraceID))
+		traceHex, carryID = hex.EncodeToString(sum[:16]), true
+	}
+	flags := "00"
+	if tc.Sampled {
+		flags = "01"
+	}
+	h.Set(TraceparentHeader, "00-"+strings.ToLower(traceHex)+"-"+randomHex(8)+"-"+flags)
+
+	members := make([]string, 0, len(tc.Metadata)+len(tc.Baggage)+2)
+	if carryID {
+		members = append(members, baggageTraceID+"="+url.PathEscape(tc.TraceID))
+	}
+	if tc.AgentID != "" {
+		members = append(members, baggageAgentID+"="+url.PathEscape(tc.AgentID))
+	}
+	for k, v := range tc.Metadata {
+		members = append(members, baggageSessionMeta+url.PathEscape(k)+"="+url.PathEscape(v))
+	}
+	for k, v := range tc.Baggage {
+		members = append(members, k+"="+url.PathEscape(v))
+	}
+	sort.Strings(members)
+	if len(members) > 0 {
+		h.Set(BaggageHeader, strings.Join(members, ","))
+	}
+}
+
+// ParseTraceHeaders reads traceparent and baggage from h. It returns nil and no error when h
+// carries no traceparent; a malformed traceparent is an error.
+func ParseTraceHeaders(h http.Header) (*TraceContext, error) {
+	tp := strings.TrimSpace(h.Get(TraceparentHeader))
+	if tp == "" {
+		return nil, nil
+	}
+	parts := strings.Split(tp, "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" || !isHex(parts[1], 32) || parts[1] == strings.Repeat("0", 32) || !isHex(parts[2], 16) || !isHex(parts[3], 2) ||
+		(parts[0] == "00" && len(parts) != 4) {
+		return nil, fmt.Errorf("sandarb: malformed traceparent %q", tp)
+	}
+	flags, _ := strconv.ParseUint(parts[3], 16, 8)
+	t := strings.ToLower(parts[1])
+	tc := &TraceContext{
+		TraceID:  t[0:8] + "-" + t[8:12] + "-" + t[12:16] + "-" + t[16:20] + "-" + t[20:32],
+		ParentID: strings.ToLower(parts[2]),
+		Sampled:  flags&1 == 1,
+		Metadata: make(map[string]string),
+	}
+	for _, header := range h.Values(BaggageHeader) {
+		for _, member := range strings.Split(header, ",") {
+			kv := strings.SplitN(strings.SplitN(member, ";", 2)[0], "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			k := strings.TrimSpace(kv[0])
+			v, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+			if k == "" || err != nil {
+				continue
+			}
+			switch {
+			case k == baggageTraceID:
+				tc.TraceID = v
+			case k == baggageAgentID:
+				tc.AgentID = v
+			case strings.HasPrefix(k, baggageSessionMeta):
+				if name, err := url.PathUnescape(k[len(baggageSessionMeta):]); err == nil && name != "" {
+					tc.Metadata[name] = v
+				}
+			default:
+				if tc.Baggage == nil {
+					tc.Baggage = make(map[string]string)
+				}
+				tc.Baggage[k] = v
+			}
+		}
+	}
+	return tc, nil
+}
+
+// SessionFromTraceHeaders continues the session described by h's traceparent and baggage:
+// same trace ID, agent ID (unless agentID is given), and session metadata. Without a
+// traceparent a new session is started for agentID.
+func (c *Client) SessionFromTraceHeaders(h http.Header, agentID string) (*Session, error) {
+	tc, err := ParseTraceHeaders(h)
+	if err != nil {
+		return nil, err
+	}
+	if tc == nil {
+		return c.NewSession(agentID), nil
+	}
+	if agentID == "" {
+		agentID = tc.AgentID
+	}
+	s := c.NewSessionWithTrace(agentID, tc.TraceID)
+	s.parentID, s.notSampled = tc.ParentID, !tc.Sampled
+	for k, v := range tc.Metadata {
+		s.metadata[k] = v
+	}
+	s.baggage = tc.Baggage
+	return s, nil
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil || strings.Trim(hex.EncodeToString(b), "0") == "" {
+		b[n-1] = 1
+	}
+	return hex.EncodeToString(b)
+}
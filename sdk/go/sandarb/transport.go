@@ -0,0 +1,107 @@
+package sandarb
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// TransportConfig tunes connection pooling and keep-alive for the client's http.Transport.
+// Zero values fall back to DefaultTransportConfig.
+type TransportConfig struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	KeepAlive           time.Duration
+	DialTimeout         time.Duration
+	TLSHandshakeTimeout time.Duration
+	// DisableHTTP2 forces HTTP/1.1 (useful behind proxies with poor HTTP/2 support).
+	DisableHTTP2 bool
+	// DisableKeepAlives closes connections after each request.
+	DisableKeepAlives bool
+}
+
+// DefaultTransportConfig is sized for agents issuing hundreds of concurrent requests to one host.
+var DefaultTransportConfig = TransportConfig{
+	MaxIdleConns:        512,
+	MaxIdleConnsPerHost: 256,
+	IdleConnTimeout:     90 * time.Second,
+	KeepAlive:           30 * time.Second,
+	DialTimeout:         10 * time.Second,
+	TLSHandshakeTimeout: 10 * time.Second,
+}
+
+// NewTransport builds an http.Transport from cfg. The result can be shared across clients via WithTransport.
+func NewTransport(cfg TransportConfig) *http.Transport {
+	d := DefaultTransportConfig
+	if cfg.MaxIdleConns > 0 {
+		d.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		d.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		d.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.KeepAlive > 0 {
+		d.KeepAlive = cfg.KeepAlive
+	}
+	if cfg.DialTimeout > 0 {
+		d.DialTimeout = cfg.DialTimeout
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		d.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	t := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		MaxIdleConns:          d.MaxIdleConns,
+		MaxIdleConnsPerHost:   d.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       d.IdleConnTimeout,
+		TLSHandshakeTimeout:   d.TLSHandshakeTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		DisableKeepAlives:     cfg.DisableKeepAlives,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+	}
+	// On js/wasm a Transport without a dialer sends requests with the host's fetch API.
+	if runtime.GOOS != "js" {
+		t.DialContext = (&net.Dialer{Timeout: d.DialTimeout, KeepAlive: d.KeepAlive}).DialContext
+	}
+	if cfg.DisableHTTP2 {
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return t
+}
+
+var (
+	sharedTransportOnce sync.Once
+	sharedTransport     *http.Transport
+)
+
+// SharedTransport returns a process-wide transport built from DefaultTransportConfig.
+// Clients created by NewClient use it unless WithTransport or WithTransportTuning is given.
+func SharedTransport() *http.Transport {
+	sharedTransportOnce.Do(func() {
+		sharedTransport = NewTransport(DefaultTransportConfig)
+	})
+	return sharedTransport
+}
+
+// WithTransportTuning gives the client its own transport built from cfg.
+func WithTransportTuning(cfg TransportConfig) ClientOption {
+	return WithTransport(NewTransport(cfg))
+}
+
+// WithTransport sets the round tripper used by the client, e.g. a transport shared across clients.
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		if c.HTTPClient == nil {
+			c.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+		}
+		c.HTTPClient.Transport = rt
+	}
+}
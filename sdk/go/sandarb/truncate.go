@@ -0,0 +1,143 @@
+package sandarb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"unicode/utf8"
+)
+
+// ErrPayloadTooLarge is returned when an activity record exceeds the payload limit even after truncation.
+var ErrPayloadTooLarge = errors.New("sandarb: activity payload exceeds size limit")
+
+// TruncationStrategy controls how oversized inputs/outputs are shortened.
+type TruncationStrategy string
+
+const (
+	// TruncateHead keeps the beginning of the serialized payload.
+	TruncateHead TruncationStrategy = "head"
+	// TruncateTail keeps the end of the serialized payload.
+	TruncateTail TruncationStrategy = "tail"
+	// TruncateSummaryHash replaces the payload with its SHA-256, size, and a short preview.
+	TruncateSummaryHash TruncationStrategy = "summary-hash"
+)
+
+const (
+	// DefaultMaxPayloadBytes matches the audit API's request body limit.
+	DefaultMaxPayloadBytes = 1 << 20
+	truncationReserve      = 256
+	summaryPreviewBytes    = 256
+)
+
+type payloadLimit struct {
+	maxBytes int
+	strategy TruncationStrategy
+}
+
+// WithPayloadLimit caps serialized activity records at maxBytes (NewClient defaults to
+// DefaultMaxPayloadBytes with TruncateHead). Oversized inputs and outputs are truncated with
+// strategy and metadata["truncation"] records what was cut.
+func WithPayloadLimit(maxBytes int, strategy TruncationStrategy) ClientOption {
+	return func(c *Client) {
+		if maxBytes <= 0 {
+			maxBytes = DefaultMaxPayloadBytes
+		}
+		if strategy == "" {
+			strategy = TruncateHead
+		}
+		c.payloadLimit = &payloadLimit{maxBytes: maxBytes, strategy: strategy}
+	}
+}
+
+// apply truncates rec in place so its serialized size fits the limit.
+func (p *payloadLimit) apply(rec *ActivityRecord) error {
+	full, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if len(full) <= p.maxBytes {
+		return nil
+	}
+	in, _ := json.Marshal(rec.Inputs)
+	out, _ := json.Marshal(rec.Outputs)
+	avail := p.maxBytes - (len(full) - len(in) - len(out)) - truncationReserve
+	if avail < 2*truncationReserve {
+		return ErrPayloadTooLarge
+	}
+	inBudget, outBudget := avail/2, avail/2
+	if len(in) < inBudget {
+		outBudget = avail - len(in)
+	} else if len(out) < outBudget {
+		inBudget = avail - len(out)
+	}
+	marker := map[string]interface{}{
+		"strategy":       string(p.strategy),
+		"limit_bytes":    p.maxBytes,
+		"original_bytes": len(full),
+	}
+	var fields []string
+	if len(in) > inBudget {
+		rec.Inputs = p.truncate(in, inBudget)
+		fields = append(fields, "inputs")
+	}
+	if len(out) > outBudget {
+		rec.Outputs = p.truncate(out, outBudget)
+		fields = append(fields, "outputs")
+	}
+	marker["fields"] = fields
+	if rec.Metadata == nil {
+		rec.Metadata = make(map[string]interface{})
+	}
+	rec.Metadata["truncation"] = marker
+	if b, err := json.Marshal(rec); err != nil || len(b) > p.maxBytes {
+		return ErrPayloadTooLarge
+	}
+	return nil
+}
+
+// truncate returns a replacement object for serialized section data that encodes within budget bytes.
+func (p *payloadLimit) truncate(data []byte, budget int) map[string]interface{} {
+	sum := sha256.Sum256(data)
+	out := map[string]interface{}{
+		"truncated": true,
+		"bytes":     len(data),
+		"sha256":    hex.EncodeToString(sum[:]),
+	}
+	keep := budget
+	if p.strategy == TruncateSummaryHash && keep > summaryPreviewBytes {
+		keep = summaryPreviewBytes
+	}
+	key := "content"
+	if p.strategy == TruncateSummaryHash {
+		key = "preview"
+	}
+	for keep > 0 {
+		out[key] = clip(data, keep, p.strategy == TruncateTail)
+		if b, _ := json.Marshal(out); len(b) <= budget {
+			return out
+		}
+		keep = keep * 3 / 4
+	}
+	delete(out, key)
+	return out
+}
+
+// clip returns at most n bytes from the head (or tail) of data, cut on a UTF-8 boundary.
+func clip(data []byte, n int, tail bool) string {
+	if n >= len(data) {
+		return string(data)
+	}
+	if tail {
+		start := len(data) - n
+		for start < len(data) && !utf8.RuneStart(data[start]) {
+			start++
+		}
+		return string(data[start:])
+	}
+	end := n
+	for end > 0 && !utf8.RuneStart(data[end]) {
+		end--
+	}
+	return string(data[:end])
+}
@@ -0,0 +1,122 @@
+package sandarb
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Redacted replaces values of redacted keys in captured inputs and outputs.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactKeys are keys redacted by Wrap unless overridden with WithRedactKeys.
+var DefaultRedactKeys = []string{"password", "secret", "token", "api_key", "apikey", "authorization"}
+
+// WrapOption configures Wrap.
+type WrapOption func(*wrapConfig)
+
+type wrapConfig struct {
+	redactKeys []string
+	redactor   func(map[string]interface{}) map[string]interface{}
+	onLogError func(error)
+}
+
+// WithRedactKeys sets the keys (case-insensitive, matched at any depth) whose values are redacted.
+func WithRedactKeys(keys ...string) WrapOption {
+	return func(c *wrapConfig) { c.redactKeys = keys }
+}
+
+// WithRedactor sets a custom redaction function applied to captured inputs and outputs after key redaction.
+func WithRedactor(fn func(map[string]interface{}) map[string]interface{}) WrapOption {
+	return func(c *wrapConfig) { c.redactor = fn }
+}
+
+// WithLogErrorHandler is called when logging the activity fails; the wrapped call's result is unaffected.
+func WithLogErrorHandler(fn func(error)) WrapOption {
+	return func(c *wrapConfig) { c.onLogError = fn }
+}
+
+// Wrap instruments fn as a named tool: each call is timed, its input and output (or error) are
+// captured with redaction, and an activity is logged through the session when fn returns.
+func Wrap[Req, Resp any](s *Session, name string, fn func(Req) (Resp, error), opts ...WrapOption) func(Req) (Resp, error) {
+	cfg := &wrapConfig{redactKeys: DefaultRedactKeys}
+	for _, o := range opts {
+		o(cfg)
+	}
+	return func(req Req) (Resp, error) {
+		start := time.Now()
+		resp, err := fn(req)
+		elapsed := time.Since(start)
+
+		inputs := cfg.capture(req)
+		var outputs map[string]interface{}
+		if err != nil {
+			outputs = map[string]interface{}{"error": err.Error()}
+		} else {
+			outputs = cfg.capture(resp)
+		}
+		meta := s.Metadata()
+		meta["tool"] = name
+		meta["duration_ms"] = elapsed.Milliseconds()
+		meta["success"] = err == nil
+		logErr := s.client.logActivity(&ActivityRecord{
+			AgentID:  s.agentID,
+			TraceID:  s.traceID,
+			Inputs:   inputs,
+			Outputs:  outputs,
+			Metadata: meta,
+		}, nil)
+		if logErr != nil && cfg.onLogError != nil {
+			cfg.onLogError(logErr)
+		}
+		return resp, err
+	}
+}
+
+// capture converts v to a JSON object (non-objects are stored under "value") and redacts it.
+func (cfg *wrapConfig) capture(v interface{}) map[string]interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return map[string]interface{}{"capture_error": err.Error()}
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return map[string]interface{}{"capture_error": err.Error()}
+	}
+	m, ok := decoded.(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{"value": decoded}
+	}
+	redactKeys(m, cfg.redactKeys)
+	if cfg.redactor != nil {
+		m = cfg.redactor(m)
+	}
+	return m
+}
+
+// redactKeys replaces, in place, the values of keys matching any of keys (case-insensitive).
+func redactKeys(v interface{}, keys []string) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, vv := range t {
+			if matchesKey(k, keys) {
+				t[k] = Redacted
+				continue
+			}
+			redactKeys(vv, keys)
+		}
+	case []interface{}:
+		for _, vv := range t {
+			redactKeys(vv, keys)
+		}
+	}
+}
+
+func matchesKey(k string, keys []string) bool {
+	for _, rk := range keys {
+		if strings.EqualFold(k, rk) {
+			return true
+		}
+	}
+	return false
+}